@@ -1,5 +1,7 @@
 package h3geodist
 
+import "github.com/uber/h3-go/v3"
+
 // Supported H3 resolutions.
 const (
 	Level0 = iota // number of unique indexes 122
@@ -11,8 +13,8 @@ const (
 	Level6        // number of unique indexes 14117882
 )
 
-// Table of cell areas for H3 resolutions.
-var cellAreas = map[int]uint{
+// Table of the number of cells for H3 resolutions.
+var cellCounts = map[int]uint{
 	Level0: 122,
 	Level1: 842,
 	Level2: 5882,
@@ -22,46 +24,80 @@ var cellAreas = map[int]uint{
 	Level6: 14117882,
 }
 
-// Level0Area returns the area (km2) for level 0.
+// Level0Area returns the number of cells for level 0.
+//
+// Deprecated: despite the name it returns the number of cells,
+// use NumCells for the number of cells and AvgCellAreaKm2 for the area.
 func Level0Area() uint {
-	return cellArea(Level0)
+	return NumCells(Level0)
 }
 
-// Level1Area returns the area (km2) for level 1.
+// Level1Area returns the number of cells for level 1.
+//
+// Deprecated: despite the name it returns the number of cells,
+// use NumCells for the number of cells and AvgCellAreaKm2 for the area.
 func Level1Area() uint {
-	return cellArea(Level1)
+	return NumCells(Level1)
 }
 
-// Level2Area returns the area (km2) for level 2.
+// Level2Area returns the number of cells for level 2.
+//
+// Deprecated: despite the name it returns the number of cells,
+// use NumCells for the number of cells and AvgCellAreaKm2 for the area.
 func Level2Area() uint {
-	return cellArea(Level2)
+	return NumCells(Level2)
 }
 
-// Level3Area returns the area (km2) for level 3.
+// Level3Area returns the number of cells for level 3.
+//
+// Deprecated: despite the name it returns the number of cells,
+// use NumCells for the number of cells and AvgCellAreaKm2 for the area.
 func Level3Area() uint {
-	return cellArea(Level3)
+	return NumCells(Level3)
 }
 
-// Level4Area returns the area (km2) for level 4.
+// Level4Area returns the number of cells for level 4.
+//
+// Deprecated: despite the name it returns the number of cells,
+// use NumCells for the number of cells and AvgCellAreaKm2 for the area.
 func Level4Area() uint {
-	return cellArea(Level4)
+	return NumCells(Level4)
 }
 
-// Level5Area returns the area (km2) for level 5.
+// Level5Area returns the number of cells for level 5.
+//
+// Deprecated: despite the name it returns the number of cells,
+// use NumCells for the number of cells and AvgCellAreaKm2 for the area.
 func Level5Area() uint {
-	return cellArea(Level5)
+	return NumCells(Level5)
 }
 
-// Level6Area returns the area (km2) for level 6.
+// Level6Area returns the number of cells for level 6.
+//
+// Deprecated: despite the name it returns the number of cells,
+// use NumCells for the number of cells and AvgCellAreaKm2 for the area.
 func Level6Area() uint {
-	return cellArea(Level6)
+	return NumCells(Level6)
 }
 
-// cellArea returns the area (km2) for specified level.
-func cellArea(level int) uint {
-	area, found := cellAreas[level]
+// NumCells returns the number of cells for specified level.
+func NumCells(level int) uint {
+	count, found := cellCounts[level]
 	if !found {
 		return 0
 	}
-	return area
+	return count
+}
+
+// AvgCellAreaKm2 returns the average area (km2) of a cell for specified level.
+func AvgCellAreaKm2(level int) float64 {
+	if ok := validateLevel(level); !ok {
+		return 0
+	}
+	return h3.HexAreaKm2(level)
+}
+
+// CellAreaKm2 returns the exact area (km2) of the cell.
+func CellAreaKm2(cell h3.H3Index) float64 {
+	return h3.CellAreaKm2(cell)
 }
@@ -0,0 +1,81 @@
+package h3geodist
+
+import (
+	"math"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// boundaryHorizon is the number of rings around the cell
+// within which BoundaryFromLatLon searches for a host boundary.
+const boundaryHorizon = 64
+
+// Boundary is a type to represent the nearest boundary
+// between the territory of the current host and another host.
+type Boundary struct {
+	// Current is the distributed cell of the point.
+	Current Cell
+	// Across is the nearest cell owned by another host.
+	Across Cell
+	// Point is the nearest point of the boundary.
+	Point LatLon
+	// DistanceM is the geodesic distance from the point to the boundary.
+	DistanceM float64
+}
+
+// BoundaryFromLatLon returns the nearest boundary of the territory
+// owned by a different host for a geographic coordinate.
+// Unlike Neighbor.DistanceM, the distance is measured to the edges of the cells,
+// not to their centers.
+func (d *Distributed) BoundaryFromLatLon(lat float64, lon float64) (b Boundary, err error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	point := LatLon{Lat: lat, Lon: lon}
+	current, addr, ok := d.locate(point)
+	if !ok {
+		return b, ErrVNodes
+	}
+	if addr == "" {
+		return b, ErrOutOfCoverage
+	}
+	b.Current = Cell{H3ID: current, Host: addr}
+	// hot cells are searched by their shards
+	cell := h3.FromGeo(point.toGeo(), d.level)
+	b.DistanceM = math.Inf(1)
+	edgeLength := h3.EdgeLengthM(d.level)
+	var k int
+	for radius := 2; k <= boundaryHorizon; radius *= 4 {
+		if radius > boundaryHorizon {
+			radius = boundaryHorizon
+		}
+		rings := h3.KRingDistances(cell, radius)
+		for ; k < len(rings); k++ {
+			// cells at grid distance k can't be closer than that,
+			// nor can their shards, so the edge of the level bounds hot cells too
+			if b.DistanceM <= (1.5*float64(k)-2)*edgeLength/2 {
+				return b, nil
+			}
+			for _, ring := range rings[k] {
+				if !d.partition().covers(ring) {
+					continue
+				}
+				for _, other := range d.shards(ring) {
+					host, ok := d.lookup(other)
+					if !ok || host == addr {
+						continue
+					}
+					nearest, dist := nearestOnBoundary(point, h3.ToGeoBoundary(other))
+					if dist < b.DistanceM {
+						b.Across = Cell{H3ID: other, Host: host}
+						b.Point = nearest
+						b.DistanceM = dist
+					}
+				}
+			}
+		}
+	}
+	if math.IsInf(b.DistanceM, 1) {
+		return Boundary{Current: b.Current}, ErrNoBoundary
+	}
+	return b, nil
+}
@@ -0,0 +1,93 @@
+package h3geodist
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_BoundaryFromLatLon(t *testing.T) {
+	h3dist, err := New(Level6, WithVNodes(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = h3dist.Add("127.0.0.1")
+	_ = h3dist.Add("127.0.0.2")
+	_ = h3dist.Add("127.0.0.3")
+
+	points := []LatLon{
+		{Lat: 42.9325219, Lon: -72.2822266},
+		{Lat: 42.8678295, Lon: -72.2744981},
+		{Lat: 51.5072178, Lon: -0.1275862},
+		{Lat: -33.8688197, Lon: 151.2092955},
+	}
+	for _, point := range points {
+		b, err := h3dist.BoundaryFromLatLon(point.Lat, point.Lon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Across.Host == b.Current.Host {
+			t.Fatalf("have %s, want another host", b.Across.Host)
+		}
+
+		// brute force over the neighborhood
+		want := math.Inf(1)
+		for _, cell := range h3.KRing(b.Current.H3ID, 16) {
+			dcell, _ := h3dist.Lookup(cell)
+			if dcell.Host == b.Current.Host {
+				continue
+			}
+			_, dist := nearestOnBoundary(point, h3.ToGeoBoundary(cell))
+			want = math.Min(want, dist)
+		}
+		if have := b.DistanceM; math.Abs(have-want) > 1e-6 {
+			t.Fatalf("have %f, want %f distance to boundary", have, want)
+		}
+		if have, want := distanceM(point, b.Point), b.DistanceM; math.Abs(have-want) > 1e-3 {
+			t.Fatalf("have %f, want %f distance to boundary point", have, want)
+		}
+
+		_, neighbors, err := h3dist.NeighborsFromLatLon(point.Lat, point.Lon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, neighbor := range neighbors {
+			if neighbor.Cell.Host != b.Current.Host && neighbor.DistanceM < b.DistanceM {
+				t.Fatalf("have %f, want <= %f", b.DistanceM, neighbor.DistanceM)
+			}
+		}
+	}
+}
+
+func TestDistributed_BoundaryFromLatLonWithError(t *testing.T) {
+	h3dist, _ := New(Level3)
+	if _, err := h3dist.BoundaryFromLatLon(42.9325219, -72.2822266); !errors.Is(err, ErrVNodes) {
+		t.Fatalf("have %v, want %v error", err, ErrVNodes)
+	}
+	_ = h3dist.Add("127.0.0.1")
+	if _, err := h3dist.BoundaryFromLatLon(42.9325219, -72.2822266); !errors.Is(err, ErrNoBoundary) {
+		t.Fatalf("have %v, want %v error", err, ErrNoBoundary)
+	}
+}
+
+func TestNearestOnArc(t *testing.T) {
+	a := LatLon{Lat: 0, Lon: 0}
+	b := LatLon{Lat: 0, Lon: 10}
+	point, dist := nearestOnArc(LatLon{Lat: 1, Lon: 5}, a, b)
+	if math.Abs(point.Lat) > 1e-9 || math.Abs(point.Lon-5) > 1e-9 {
+		t.Fatalf("have %v, want {0 5}", point)
+	}
+	if have, want := dist, distanceM(LatLon{Lat: 1, Lon: 5}, point); math.Abs(have-want) > 1e-6 {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	point, _ = nearestOnArc(LatLon{Lat: 1, Lon: -5}, a, b)
+	if point != a {
+		t.Fatalf("have %v, want %v", point, a)
+	}
+	point, _ = nearestOnArc(LatLon{Lat: -1, Lon: 15}, a, b)
+	if point != b {
+		t.Fatalf("have %v, want %v", point, b)
+	}
+}
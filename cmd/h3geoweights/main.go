@@ -0,0 +1,60 @@
+// Command h3geoweights converts weight samples into a binary weight table
+// for h3geodist.WithWeightTable.
+//
+//	h3geoweights -in population.csv -level 5 -out population.h3w
+//	h3geoweights -in population.asc -format asc -level 4 -out population.h3w
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	h3geodist "github.com/mmadfox/go-h3geo-dist"
+)
+
+func main() {
+	in := flag.String("in", "", "input file with samples")
+	format := flag.String("format", "csv", "input format: csv (lat,lon,weight) or asc (ESRI ASCII grid)")
+	level := flag.Int("level", h3geodist.Level5, "cell level of the table")
+	out := flag.String("out", "weights.h3w", "output file")
+	flag.Parse()
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	var importer func(io.Reader, int) (h3geodist.WeightTable, error)
+	switch *format {
+	case "csv":
+		importer = h3geodist.ImportWeightsCSV
+	case "asc":
+		importer = h3geodist.ImportWeightsASCIIGrid
+	default:
+		log.Fatalf("unsupported format %s", *format)
+	}
+
+	table, err := importer(f, *level)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := h3geodist.SaveWeightTable(*out, table); err != nil {
+		log.Fatal(err)
+	}
+
+	var total float64
+	for _, weight := range table {
+		total += weight
+	}
+	fmt.Printf("level=%d, cells=%d of %d, total weight=%.2f, saved to %s\n",
+		*level, len(table), h3geodist.NumCells(*level), total, *out)
+}
@@ -0,0 +1,80 @@
+package h3geodist
+
+import (
+	"sort"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// WithCoverage restricts the distributed domain to the cells.
+// Cells of a finer level are replaced by their parents at the distribution level,
+// cells of a coarser level by their children.
+// Lookups outside the coverage fail with ErrOutOfCoverage,
+// EachCell iterates only covered cells and vnodes are balanced
+// by the number of covered cells. Multiple coverages are merged.
+func WithCoverage(cells ...h3.H3Index) Option {
+	return func(d *Distributed) {
+		if d.coverage == nil {
+			d.coverage = make([]h3.H3Index, 0, len(cells))
+		}
+		for _, cell := range cells {
+			switch res := h3.Resolution(cell); {
+			case res == d.level:
+				d.coverage = append(d.coverage, cell)
+			case res > d.level:
+				d.coverage = append(d.coverage, h3.ToParent(cell, d.level))
+			default:
+				d.coverage = append(d.coverage, h3.ToChildren(cell, d.level)...)
+			}
+		}
+	}
+}
+
+// WithCoveragePolygons restricts the distributed domain to the polygons,
+// see WithCoverage. Cells of the distribution level whose centers are
+// within a polygon are covered, for a polygon smaller than a cell
+// the cells containing its vertices are covered.
+func WithCoveragePolygons(polygons ...Polygon) Option {
+	return func(d *Distributed) {
+		if d.coverage == nil {
+			d.coverage = make([]h3.H3Index, 0, 64)
+		}
+		for _, polygon := range polygons {
+			d.coverage = append(d.coverage, regionCells(polygon, d.level)...)
+		}
+	}
+}
+
+// Covers returns TRUE if the cell is within the coverage.
+// Without a coverage each cell is covered.
+func (d *Distributed) Covers(cell h3.H3Index) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.partition().covers(cell)
+}
+
+// Coverage returns the sorted covered cells at the distribution level,
+// or nil without a coverage.
+func (d *Distributed) Coverage() []h3.H3Index {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.coverage == nil {
+		return nil
+	}
+	return append([]h3.H3Index(nil), d.coverage...)
+}
+
+// normalizeCoverage sorts the cells and removes duplicates.
+func normalizeCoverage(cells []h3.H3Index) []h3.H3Index {
+	sort.Slice(cells, func(i, j int) bool {
+		return cells[i] < cells[j]
+	})
+	res := cells[:0]
+	for i, cell := range cells {
+		if i > 0 && cell == cells[i-1] {
+			continue
+		}
+		res = append(res, cell)
+	}
+	return res
+}
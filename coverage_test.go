@@ -0,0 +1,117 @@
+package h3geodist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_WithCoverage(t *testing.T) {
+	// France and Spain
+	europe := []Polygon{
+		{Outer: []LatLon{{Lat: 43, Lon: -4}, {Lat: 43, Lon: 7}, {Lat: 51, Lon: 7}, {Lat: 51, Lon: -4}}},
+		{Outer: []LatLon{{Lat: 36, Lon: -9}, {Lat: 36, Lon: 3}, {Lat: 43, Lon: 3}, {Lat: 43, Lon: -9}}},
+	}
+	tokyo := h3.FromGeo(h3.GeoCoord{Latitude: 35.6762, Longitude: 139.6503}, Level5)
+	h3dist, err := New(Level3, WithVNodes(128),
+		WithCoveragePolygons(europe...),
+		WithCoverage(tokyo, tokyo),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		_ = h3dist.Add(fmt.Sprintf("host-%d.com", i))
+	}
+	coverage := h3dist.Coverage()
+	if len(coverage) == 0 {
+		t.Fatalf("have 0, want > 0 covered cells")
+	}
+	for i := 1; i < len(coverage); i++ {
+		if coverage[i-1] >= coverage[i] {
+			t.Fatalf("coverage is not sorted or has duplicates")
+		}
+	}
+	if !h3dist.Covers(h3.ToParent(tokyo, Level3)) || !h3dist.Covers(tokyo) {
+		t.Fatalf("have false, want true")
+	}
+
+	if _, err := h3dist.LookupFromLatLon(48.8566, 2.3522); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h3dist.LookupFromLatLon(40.7128, -74.0060); !errors.Is(err, ErrOutOfCoverage) {
+		t.Fatalf("have %v, want %v", err, ErrOutOfCoverage)
+	}
+	newYork := h3.FromGeo(h3.GeoCoord{Latitude: 40.7128, Longitude: -74.0060}, Level4)
+	if _, ok := h3dist.Lookup(newYork); ok {
+		t.Fatalf("have true, want false")
+	}
+	if _, err := h3dist.WhereIsMyParent(newYork); !errors.Is(err, ErrOutOfCoverage) {
+		t.Fatalf("have %v, want %v", err, ErrOutOfCoverage)
+	}
+	if _, err := h3dist.ReplicaFor(newYork, 2); !errors.Is(err, ErrOutOfCoverage) {
+		t.Fatalf("have %v, want %v", err, ErrOutOfCoverage)
+	}
+	if _, _, err := h3dist.NeighborsFromLatLon(40.7128, -74.0060); !errors.Is(err, ErrOutOfCoverage) {
+		t.Fatalf("have %v, want %v", err, ErrOutOfCoverage)
+	}
+	if _, err := h3dist.BoundaryFromLatLon(40.7128, -74.0060); !errors.Is(err, ErrOutOfCoverage) {
+		t.Fatalf("have %v, want %v", err, ErrOutOfCoverage)
+	}
+
+	var cells int
+	h3dist.EachCell(func(c Cell) {
+		cells++
+		if !h3dist.Covers(c.H3ID) {
+			t.Fatalf("h3dist.Covers(%v) => false, expected true", c)
+		}
+	})
+	if have, want := cells, len(coverage); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+
+	// balance is computed over the covered cells
+	report := h3dist.DistributionReport()
+	if have, want := report.Cells, len(coverage); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+	var load float64
+	for _, info := range h3dist.Stats() {
+		load += info.Load
+	}
+	if have, want := load, float64(len(coverage)); have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+
+	// the path leaves the coverage at the sea
+	segments, err := h3dist.LookupPath([]LatLon{{Lat: 48.8566, Lon: 2.3522}, {Lat: 51.5, Lon: -8}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := segments[len(segments)-1].Host; have != "" {
+		t.Fatalf("have %s, want empty host", have)
+	}
+
+	// the snapshot keeps the coverage
+	data, err := json.Marshal(h3dist.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := snapshot.Lookup(newYork); ok {
+		t.Fatalf("have true, want false")
+	}
+	cells = 0
+	snapshot.EachCell(func(c Cell) {
+		cells++
+	})
+	if have, want := cells, len(coverage); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+}
@@ -18,22 +18,65 @@ var (
 
 	// ErrVNodes returns when there are no virtual nodes.
 	ErrVNodes = errors.New("h3geodist: vnodes not found")
+
+	// ErrEmptyPath returns when the path has no points.
+	ErrEmptyPath = errors.New("h3geodist: empty path")
+
+	// ErrSpeed returns when the speed of a moving object is not positive.
+	ErrSpeed = errors.New("h3geodist: speed must be positive")
+
+	// ErrNoHandoff returns when the host boundary is not found within the horizon.
+	ErrNoHandoff = errors.New("h3geodist: no handoff within horizon")
+
+	// ErrNoBoundary returns when the host boundary is not found within the horizon.
+	ErrNoBoundary = errors.New("h3geodist: no boundary within horizon")
+
+	// ErrNodeNotFound returns when the node is not in the list of nodes.
+	ErrNodeNotFound = errors.New("h3geodist: node not found")
+
+	// ErrOutOfCoverage returns when the cell is outside the coverage.
+	ErrOutOfCoverage = errors.New("h3geodist: out of coverage")
+
+	// ErrCooldown returns when the rebalancing round is requested during the cooldown.
+	ErrCooldown = errors.New("h3geodist: rebalancer cooldown")
+
+	// ErrNoLoad returns when there is no measured load of vnodes.
+	ErrNoLoad = errors.New("h3geodist: no vnode load")
+
+	// ErrVersionConflict returns when the stored topology was changed by another writer.
+	ErrVersionConflict = errors.New("h3geodist: topology version conflict")
+
+	// ErrPoolClosed returns when the client pool is closed.
+	ErrPoolClosed = errors.New("h3geodist: pool closed")
 )
 
 // Distributed holds information about nodes,
 // and scheduler of virtual nodes with replicas.
 // Thread-safe.
 type Distributed struct {
-	mu         sync.RWMutex
-	replFactor int
-	loadFactor float64
-	vnodes     uint64
-	ring       map[uint64]*node
-	index      map[int]*node
-	hashes     []uint64
-	nodes      []*node
-	level      int
-	stats      map[string]float64
+	mu          sync.RWMutex
+	replFactor  int
+	loadFactor  float64
+	vnodes      uint64
+	ring        map[uint64]*node
+	index       map[int]*node
+	hashes      []uint64
+	nodes       []*node
+	level       int
+	stats       map[string]float64
+	weightFn    WeightFunc
+	weights     []float64
+	totalWeight float64
+	hot         map[h3.H3Index]int
+	regions     map[h3.H3Index]int
+	coverage    []h3.H3Index
+	traffic     *traffic
+	moved       map[uint64]string
+	stepSize    int
+	target      map[int]*node
+	epoch       uint64
+	watchers    map[*watcher]struct{}
+	self        string
 }
 
 // Cell is a type to represent a distributed cell
@@ -71,8 +114,7 @@ func Default() *Distributed {
 // with specified cell level and options.
 func New(cellLevel int, opts ...Option) (*Distributed, error) {
 	if ok := validateLevel(cellLevel); !ok {
-		return nil, fmt.Errorf("h3geodist: unsupported level - got %d, expected [%d-%d]",
-			cellLevel, Level0, Level6)
+		return nil, unsupportedLevel(cellLevel)
 	}
 	h3dist := &Distributed{
 		loadFactor: DefaultLoadFactor,
@@ -86,6 +128,15 @@ func New(cellLevel int, opts ...Option) (*Distributed, error) {
 	for _, f := range opts {
 		f(h3dist)
 	}
+	for _, level := range h3dist.regions {
+		if err := h3dist.validateSplitLevel(level); err != nil {
+			return nil, err
+		}
+	}
+	if h3dist.coverage != nil {
+		h3dist.coverage = normalizeCoverage(h3dist.coverage)
+	}
+	h3dist.updateWeights()
 	return h3dist, nil
 }
 
@@ -101,7 +152,9 @@ func (d *Distributed) NumReplica() int {
 	return d.replFactor
 }
 
-// Stats returns load distribution by nodes.
+// Stats returns load distribution by nodes sorted by host.
+// Load is the number of vnodes owned by the node,
+// or their total weight when cell weights or a coverage are set.
 func (d *Distributed) Stats() []NodeInfo {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -112,6 +165,9 @@ func (d *Distributed) Stats() []NodeInfo {
 			Load: load,
 		})
 	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Host < stats[j].Host
+	})
 	return stats
 }
 
@@ -132,16 +188,21 @@ func (d *Distributed) Nodes() []string {
 }
 
 // Lookup returns distributed cell.
+// A cell within a hot cell resolves to its shard,
+// a cell outside the coverage is not found.
 func (d *Distributed) Lookup(cell h3.H3Index) (Cell, bool) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	if len(d.nodes) == 0 {
+	p := d.partition()
+	if len(d.nodes) == 0 || !p.covers(cell) {
 		return Cell{}, false
 	}
+	cell = p.shard(cell)
 	addr, ok := d.lookup(cell)
 	if !ok {
 		return Cell{}, false
 	}
+	d.countLookup(cell, addr)
 	return Cell{H3ID: cell, Host: addr}, true
 }
 
@@ -149,7 +210,11 @@ func (d *Distributed) Lookup(cell h3.H3Index) (Cell, bool) {
 func (d *Distributed) IsOwned(c Cell) bool {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	addr, ok := d.lookup(c.H3ID)
+	p := d.partition()
+	if !p.covers(c.H3ID) {
+		return false
+	}
+	addr, ok := d.lookup(p.shard(c.H3ID))
 	if !ok {
 		return false
 	}
@@ -158,6 +223,8 @@ func (d *Distributed) IsOwned(c Cell) bool {
 
 // WhereIsMyParent finds and returns parent distributed cell.
 // The child object must be less resolution than the parent's parent.
+// Within a hot cell the parent is the shard, so the child
+// must be at the split level or finer.
 func (d *Distributed) WhereIsMyParent(child h3.H3Index) (c Cell, err error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -167,6 +234,16 @@ func (d *Distributed) WhereIsMyParent(child h3.H3Index) (c Cell, err error) {
 			curLevel, d.level)
 	}
 	cell := h3.ToParent(child, d.level)
+	if !d.partition().covers(cell) {
+		return c, ErrOutOfCoverage
+	}
+	if split, ok := d.hot[cell]; ok {
+		if curLevel < split {
+			return c, fmt.Errorf("h3geodist: child resolution got %d, expected >= %d within hot cell",
+				curLevel, split)
+		}
+		cell = h3.ToParent(child, split)
+	}
 	addr, ok := d.lookup(cell)
 	if !ok {
 		return c, ErrVNodes
@@ -180,11 +257,16 @@ func (d *Distributed) WhereIsMyParent(child h3.H3Index) (c Cell, err error) {
 func (d *Distributed) LookupFromLatLon(lat float64, lon float64) (c Cell, err error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	cell := h3.FromGeo(h3.GeoCoord{Latitude: lat, Longitude: lon}, d.level)
+	p := d.partition()
+	cell := p.shardAt(LatLon{Lat: lat, Lon: lon})
+	if !p.covers(cell) {
+		return c, ErrOutOfCoverage
+	}
 	addr, ok := d.lookup(cell)
 	if !ok {
 		return c, ErrVNodes
 	}
+	d.countLookup(cell, addr)
 	return Cell{H3ID: cell, Host: addr}, nil
 }
 
@@ -202,26 +284,31 @@ func (d *Distributed) NeighborsFromLatLon(lat float64, lon float64) (target Cell
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 	src := h3.GeoCoord{Latitude: lat, Longitude: lon}
+	p := d.partition()
 	cell := h3.FromGeo(src, d.level)
-	addr, ok := d.lookup(cell)
+	if !p.covers(cell) {
+		return target, nil, ErrOutOfCoverage
+	}
+	target.H3ID = p.shardAt(latLonFromGeo(src))
+	addr, ok := d.lookup(target.H3ID)
 	if !ok {
 		return target, nil, ErrVNodes
 	}
 	target.Host = addr
-	target.H3ID = cell
 	ring := h3.KRing(cell, 1)
 	neighbors = make([]Neighbor, 0, len(ring))
 	for i := 0; i < len(ring); i++ {
-		if !h3.AreNeighbors(cell, ring[i]) {
+		if !h3.AreNeighbors(cell, ring[i]) || !p.covers(ring[i]) {
 			continue
 		}
-		addr, ok := d.lookup(ring[i])
+		neighbor := p.shard(ring[i])
+		addr, ok := d.lookup(neighbor)
 		if !ok {
 			continue
 		}
-		dest := h3.ToGeo(ring[i])
+		dest := h3.ToGeo(neighbor)
 		neighbors = append(neighbors, Neighbor{
-			Cell:      Cell{Host: addr, H3ID: ring[i]},
+			Cell:      Cell{Host: addr, H3ID: neighbor},
 			DistanceM: h3.PointDistM(src, dest),
 		})
 	}
@@ -243,7 +330,11 @@ func (d *Distributed) ReplicaFor(cell h3.H3Index, n int) ([]string, error) {
 
 	var mykey uint64
 	var next int
-	myaddr, ok := d.lookup(cell)
+	p := d.partition()
+	if !p.covers(cell) {
+		return nil, ErrOutOfCoverage
+	}
+	myaddr, ok := d.lookup(p.shard(cell))
 	if !ok {
 		return nil, ErrVNodes
 	}
@@ -285,12 +376,18 @@ func (d *Distributed) LookupMany(cell []h3.H3Index, iter func(c Cell) bool) bool
 	if len(cell) == 0 || len(d.nodes) == 0 {
 		return false
 	}
+	p := d.partition()
 	for i := 0; i < len(cell); i++ {
-		addr, ok := d.lookup(cell[i])
+		if !p.covers(cell[i]) {
+			continue
+		}
+		c := p.shard(cell[i])
+		addr, ok := d.lookup(c)
 		if !ok {
 			continue
 		}
-		if ok := iter(Cell{H3ID: cell[i], Host: addr}); !ok {
+		d.countLookup(c, addr)
+		if ok := iter(Cell{H3ID: c, Host: addr}); !ok {
 			return false
 		}
 	}
@@ -332,13 +429,14 @@ func (d *Distributed) Addr(vnode uint64) (addr string, ok bool) {
 }
 
 // EachCell iterate each distributed cell, calling fn for each cell.
+// Only covered cells are iterated, hot cells are replaced by their shards.
 func (d *Distributed) EachCell(iter func(c Cell)) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 	if len(d.nodes) == 0 {
 		return
 	}
-	Iter(d.level, func(_ uint, cell h3.H3Index) {
+	d.partition().eachShard(func(cell h3.H3Index) {
 		addr, ok := d.lookup(cell)
 		if !ok {
 			return
@@ -354,6 +452,7 @@ func (d *Distributed) Add(addr string) error {
 	if d.exist(addr) {
 		return nil
 	}
+	defer d.publish(d.state())
 	newNode := &node{addr: addr}
 	d.nodes = append(d.nodes, newNode)
 	d.add(newNode)
@@ -367,10 +466,43 @@ func (d *Distributed) Remove(addr string) {
 	if !d.exist(addr) {
 		return
 	}
+	defer d.publish(d.state())
 	d.remove(addr)
 	_ = d.distribute()
 }
 
+// SetNodes replaces the list of nodes in one batch,
+// so the vnodes are distributed once.
+func (d *Distributed) SetNodes(addrs []string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.publish(d.state())
+	keep := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		keep[addr] = struct{}{}
+	}
+	var changed bool
+	for _, n := range append([]*node(nil), d.nodes...) {
+		if _, ok := keep[n.addr]; !ok {
+			d.remove(n.addr)
+			changed = true
+		}
+	}
+	for _, addr := range addrs {
+		if d.exist(addr) {
+			continue
+		}
+		newNode := &node{addr: addr}
+		d.nodes = append(d.nodes, newNode)
+		d.add(newNode)
+		changed = true
+	}
+	if !changed || len(d.nodes) == 0 {
+		return nil
+	}
+	return d.distribute()
+}
+
 func (d *Distributed) lookup(cell h3.H3Index) (addr string, ok bool) {
 	hashKey := uint2hash(uint64(cell))
 	idx := int(hashKey % d.vnodes)
@@ -383,6 +515,15 @@ func (d *Distributed) lookup(cell h3.H3Index) (addr string, ok bool) {
 	return
 }
 
+func (d *Distributed) node(addr string) *node {
+	for i := 0; i < len(d.nodes); i++ {
+		if addr == d.nodes[i].addr {
+			return d.nodes[i]
+		}
+	}
+	return nil
+}
+
 func (d *Distributed) exist(addr string) (ok bool) {
 	for i := 0; i < len(d.nodes); i++ {
 		if addr == d.nodes[i].addr {
@@ -399,17 +540,27 @@ func (d *Distributed) distribute() error {
 	for vnode := uint64(0); vnode < d.vnodes; vnode++ {
 		nodeIndex := d.findNodeIndex(uint2hash(vnode))
 		avgload := d.AvgLoad()
+		weight := d.vnodeWeight(vnode)
 		var next int
 		for {
 			next++
 			if next >= len(d.hashes) {
-				return ErrNoSlots
+				// no node fits a weighted vnode under the average load,
+				// the vnode goes to the least loaded node
+				if d.weights == nil || len(d.nodes) == 0 {
+					return ErrNoSlots
+				}
+				node := d.leastLoaded(stats)
+				index[int(vnode)] = node
+				stats[node.addr] += weight
+				break
 			}
 			node := d.ring[d.hashes[nodeIndex]]
 			load := stats[node.addr]
-			if load+1 <= avgload {
+			// a vnode heavier than the average load goes to an empty node
+			if load+weight <= avgload || (d.weights != nil && load == 0) {
 				index[int(vnode)] = node
-				stats[node.addr]++
+				stats[node.addr] += weight
 				break
 			}
 			nodeIndex++
@@ -418,16 +569,53 @@ func (d *Distributed) distribute() error {
 			}
 		}
 	}
+	// vnodes moved by the rebalancer stay on their hosts
+	for vnode, addr := range d.moved {
+		from, ok := index[int(vnode)]
+		to := d.node(addr)
+		if !ok || to == nil || from == to {
+			delete(d.moved, vnode)
+			continue
+		}
+		weight := d.vnodeWeight(vnode)
+		stats[from.addr] -= weight
+		stats[to.addr] += weight
+		index[int(vnode)] = to
+	}
+	// in the throttled mode the live index advances by Step
+	if d.stepSize > 0 && len(d.index) > 0 {
+		d.target = index
+		d.stats = d.indexStats()
+		return nil
+	}
 	d.index = index
 	d.stats = stats
+	d.target = nil
 	return nil
 }
 
-// AvgLoad returns the average load.
+// leastLoaded returns the node with the least load in stats,
+// the first in the order of addition on ties.
+func (d *Distributed) leastLoaded(stats map[string]float64) *node {
+	var least *node
+	for _, n := range d.nodes {
+		if least == nil || stats[n.addr] < stats[least.addr] {
+			least = n
+		}
+	}
+	return least
+}
+
+// AvgLoad returns the average load multiplied by the load factor.
+// Without cell weights and a coverage the load is the number of vnodes,
+// otherwise it is the total weight of the vnodes cells.
 func (d *Distributed) AvgLoad() float64 {
 	if len(d.nodes) == 0 {
 		return 0
 	}
+	if d.weights != nil {
+		return d.totalWeight / float64(len(d.nodes)) * d.loadFactor
+	}
 	return math.Ceil(float64(d.vnodes/uint64(len(d.nodes))) * d.loadFactor)
 }
 
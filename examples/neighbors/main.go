@@ -31,24 +31,22 @@ func main() {
 			curHost = target.Host
 
 			neighbor0 := neighbors[0]
-			neighbor1 := neighbors[1]
 
-			fmt.Printf("host=%s\ncurrent=%s\nfrom=%s - %.2f%s \n--\n",
+			boundary, err := h3dist.BoundaryFromLatLon(cord[0], cord[1])
+			if err != nil {
+				panic(err)
+			}
+
+			fmt.Printf("host=%s\ncurrent=%s\nfrom=%s\nboundary=%.2fm, across=%s \n--\n",
 				target.Host,
 				target.HexID(),
 				neighbor0.Cell.HexID(),
-				toPercent(neighbor0.DistanceM, neighbor1.DistanceM), "%")
+				boundary.DistanceM,
+				boundary.Across.Host)
 		}
 	}
 }
 
-func toPercent(v1, v2 float64) float64 {
-	if v2 == 0 || v1 == 0 {
-		return 0
-	}
-	return ((v2 - v1) / v2) * 100
-}
-
 func coordsFromString(s string) [][2]float64 {
 	lines := strings.Split(s, "\n")
 	res := make([][2]float64, 0)
@@ -9,7 +9,7 @@ import (
 
 func main() {
 	level := h3geodist.Level1
-	area := h3geodist.Level1Area()
+	area := h3geodist.NumCells(level)
 
 	h3dist, err := h3geodist.New(level)
 	if err != nil {
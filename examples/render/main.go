@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	h3geodist "github.com/mmadfox/go-h3geo-dist"
+)
+
+func main() {
+	h3dist, err := h3geodist.New(h3geodist.Level2)
+	if err != nil {
+		panic(err)
+	}
+
+	_ = h3dist.Add("127.0.0.1")
+	_ = h3dist.Add("127.0.0.2")
+	_ = h3dist.Add("127.0.0.3")
+
+	// layout before the topology change
+	before := h3dist.Snapshot()
+
+	_ = h3dist.Add("127.0.0.4")
+
+	render("before.html", before, "before Add(127.0.0.4)")
+	render("after.html", h3dist, "after Add(127.0.0.4)")
+}
+
+func render(filename string, layout h3geodist.Layout, title string) {
+	f, err := os.Create(filename)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	if err := h3geodist.RenderHTML(f, layout, title); err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s: %s\n", title, filename)
+}
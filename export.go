@@ -0,0 +1,390 @@
+package h3geodist
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// Format is a type to represent an export format of the ownership map.
+type Format int
+
+const (
+	// FormatGeoJSON is a GeoJSON FeatureCollection.
+	FormatGeoJSON Format = iota
+	// FormatNDJSON is a newline-delimited GeoJSON, one Feature per line.
+	FormatNDJSON
+	// FormatWKT is a CSV with properties and a WKT geometry column.
+	FormatWKT
+	// FormatKML is a KML document with a Placemark per feature.
+	FormatKML
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatGeoJSON:
+		return "geojson"
+	case FormatNDJSON:
+		return "ndjson"
+	case FormatWKT:
+		return "wkt"
+	case FormatKML:
+		return "kml"
+	default:
+		return "Format(" + strconv.Itoa(int(f)) + ")"
+	}
+}
+
+// ExportCells streams one feature per distributed cell to w
+// with the properties h3, host and vnode.
+// Cells are exported from a snapshot, so writes to w do not block
+// changes of the topology, and features are written as cells are iterated,
+// so memory usage does not depend on the level.
+func (d *Distributed) ExportCells(w io.Writer, format Format) error {
+	enc, err := newFeatureEncoder(w, format)
+	if err != nil {
+		return err
+	}
+	s := d.Snapshot()
+	enc.begin()
+	s.EachCell(func(c Cell) {
+		boundary := h3.ToGeoBoundary(c.H3ID)
+		outer := make([]LatLon, 0, len(boundary))
+		for i := 0; i < len(boundary); i++ {
+			outer = append(outer, latLonFromGeo(boundary[i]))
+		}
+		enc.feature(feature{
+			props: []property{
+				{key: "h3", value: c.HexID()},
+				{key: "host", value: c.Host},
+				{key: "vnode", value: int(uint2hash(uint64(c.H3ID)) % s.VNodes)},
+			},
+			polygons: []Polygon{{Outer: outer}},
+		})
+	})
+	enc.end()
+	return enc.flush()
+}
+
+// ExportHosts streams one feature per host with the merged territory to w
+// with the properties host, cells and area_km2.
+// Territories are built from a single snapshot, so they are consistent
+// with each other and hosts draining in the throttled mode are exported too.
+// Only the territory of a single host is held in memory at a time.
+func (d *Distributed) ExportHosts(w io.Writer, format Format) error {
+	enc, err := newFeatureEncoder(w, format)
+	if err != nil {
+		return err
+	}
+	s := d.Snapshot()
+	lookup := func(cell h3.H3Index) (string, bool) {
+		addr := s.Owners[uint2hash(uint64(cell))%s.VNodes]
+		return addr, addr != ""
+	}
+	enc.begin()
+	for _, host := range s.Nodes() {
+		t := territoryOf(s.partition(), host, lookup)
+		enc.feature(feature{
+			props: []property{
+				{key: "host", value: t.Host},
+				{key: "cells", value: t.Cells},
+				{key: "area_km2", value: t.AreaKm2},
+			},
+			polygons: t.Polygons,
+		})
+	}
+	enc.end()
+	return enc.flush()
+}
+
+type property struct {
+	key   string
+	value interface{}
+}
+
+type feature struct {
+	props    []property
+	polygons []Polygon
+}
+
+// featureEncoder writes features in a specific format.
+// Write errors are kept by bufio.Writer and returned by flush.
+type featureEncoder interface {
+	begin()
+	feature(f feature)
+	end()
+	flush() error
+}
+
+func newFeatureEncoder(w io.Writer, format Format) (featureEncoder, error) {
+	bw := bufio.NewWriter(w)
+	switch format {
+	case FormatGeoJSON:
+		return &geoJSONEncoder{w: bw, collection: true}, nil
+	case FormatNDJSON:
+		return &geoJSONEncoder{w: bw}, nil
+	case FormatWKT:
+		return &wktEncoder{w: bw}, nil
+	case FormatKML:
+		return &kmlEncoder{w: bw}, nil
+	default:
+		return nil, fmt.Errorf("h3geodist: unsupported export format %v", format)
+	}
+}
+
+type geoJSONEncoder struct {
+	w          *bufio.Writer
+	collection bool
+	next       bool
+}
+
+func (e *geoJSONEncoder) begin() {
+	if e.collection {
+		_, _ = e.w.WriteString(`{"type":"FeatureCollection","features":[`)
+	}
+}
+
+func (e *geoJSONEncoder) feature(f feature) {
+	if e.collection && e.next {
+		_ = e.w.WriteByte(',')
+	}
+	e.next = true
+	_, _ = e.w.WriteString(`{"type":"Feature","properties":{`)
+	for i, prop := range f.props {
+		if i > 0 {
+			_ = e.w.WriteByte(',')
+		}
+		writeJSON(e.w, prop.key)
+		_ = e.w.WriteByte(':')
+		writeJSON(e.w, prop.value)
+	}
+	_, _ = e.w.WriteString(`},"geometry":`)
+	if len(f.polygons) == 1 {
+		_, _ = e.w.WriteString(`{"type":"Polygon","coordinates":`)
+		e.polygon(f.polygons[0])
+	} else {
+		_, _ = e.w.WriteString(`{"type":"MultiPolygon","coordinates":[`)
+		for i, polygon := range f.polygons {
+			if i > 0 {
+				_ = e.w.WriteByte(',')
+			}
+			e.polygon(polygon)
+		}
+		_ = e.w.WriteByte(']')
+	}
+	_, _ = e.w.WriteString(`}}`)
+	if !e.collection {
+		_ = e.w.WriteByte('\n')
+	}
+}
+
+func (e *geoJSONEncoder) polygon(p Polygon) {
+	_ = e.w.WriteByte('[')
+	e.ring(p.Outer)
+	for _, hole := range p.Holes {
+		_ = e.w.WriteByte(',')
+		e.ring(hole)
+	}
+	_ = e.w.WriteByte(']')
+}
+
+func (e *geoJSONEncoder) ring(loop []LatLon) {
+	_ = e.w.WriteByte('[')
+	for i, ll := range closeLoop(loop) {
+		if i > 0 {
+			_ = e.w.WriteByte(',')
+		}
+		_ = e.w.WriteByte('[')
+		writeFloat(e.w, ll.Lon)
+		_ = e.w.WriteByte(',')
+		writeFloat(e.w, ll.Lat)
+		_ = e.w.WriteByte(']')
+	}
+	_ = e.w.WriteByte(']')
+}
+
+func (e *geoJSONEncoder) end() {
+	if e.collection {
+		_, _ = e.w.WriteString("]}\n")
+	}
+}
+
+func (e *geoJSONEncoder) flush() error {
+	return e.w.Flush()
+}
+
+type wktEncoder struct {
+	w      *bufio.Writer
+	header bool
+}
+
+func (e *wktEncoder) begin() {}
+
+func (e *wktEncoder) feature(f feature) {
+	if !e.header {
+		e.header = true
+		for _, prop := range f.props {
+			_, _ = e.w.WriteString(prop.key)
+			_ = e.w.WriteByte(',')
+		}
+		_, _ = e.w.WriteString("wkt\n")
+	}
+	for _, prop := range f.props {
+		writeCSV(e.w, prop.value)
+		_ = e.w.WriteByte(',')
+	}
+	_ = e.w.WriteByte('"')
+	switch len(f.polygons) {
+	case 0:
+		_, _ = e.w.WriteString("MULTIPOLYGON EMPTY")
+	case 1:
+		_, _ = e.w.WriteString("POLYGON ")
+		e.polygon(f.polygons[0])
+	default:
+		_, _ = e.w.WriteString("MULTIPOLYGON (")
+		for i, polygon := range f.polygons {
+			if i > 0 {
+				_, _ = e.w.WriteString(", ")
+			}
+			e.polygon(polygon)
+		}
+		_ = e.w.WriteByte(')')
+	}
+	_, _ = e.w.WriteString("\"\n")
+}
+
+func (e *wktEncoder) polygon(p Polygon) {
+	_ = e.w.WriteByte('(')
+	e.ring(p.Outer)
+	for _, hole := range p.Holes {
+		_, _ = e.w.WriteString(", ")
+		e.ring(hole)
+	}
+	_ = e.w.WriteByte(')')
+}
+
+func (e *wktEncoder) ring(loop []LatLon) {
+	_ = e.w.WriteByte('(')
+	for i, ll := range closeLoop(loop) {
+		if i > 0 {
+			_, _ = e.w.WriteString(", ")
+		}
+		writeFloat(e.w, ll.Lon)
+		_ = e.w.WriteByte(' ')
+		writeFloat(e.w, ll.Lat)
+	}
+	_ = e.w.WriteByte(')')
+}
+
+func (e *wktEncoder) end() {}
+
+func (e *wktEncoder) flush() error {
+	return e.w.Flush()
+}
+
+type kmlEncoder struct {
+	w *bufio.Writer
+}
+
+func (e *kmlEncoder) begin() {
+	_, _ = e.w.WriteString(xml.Header)
+	_, _ = e.w.WriteString(`<kml xmlns="http://www.opengis.net/kml/2.2"><Document>` + "\n")
+}
+
+func (e *kmlEncoder) feature(f feature) {
+	_, _ = e.w.WriteString("<Placemark><name>")
+	writeXML(e.w, fmt.Sprint(f.props[0].value))
+	_, _ = e.w.WriteString("</name><ExtendedData>")
+	for _, prop := range f.props {
+		_, _ = e.w.WriteString(`<Data name="`)
+		writeXML(e.w, prop.key)
+		_, _ = e.w.WriteString(`"><value>`)
+		writeXML(e.w, fmt.Sprint(prop.value))
+		_, _ = e.w.WriteString("</value></Data>")
+	}
+	_, _ = e.w.WriteString("</ExtendedData>")
+	if len(f.polygons) != 1 {
+		_, _ = e.w.WriteString("<MultiGeometry>")
+	}
+	for _, polygon := range f.polygons {
+		_, _ = e.w.WriteString("<Polygon><outerBoundaryIs>")
+		e.ring(polygon.Outer)
+		_, _ = e.w.WriteString("</outerBoundaryIs>")
+		for _, hole := range polygon.Holes {
+			_, _ = e.w.WriteString("<innerBoundaryIs>")
+			e.ring(hole)
+			_, _ = e.w.WriteString("</innerBoundaryIs>")
+		}
+		_, _ = e.w.WriteString("</Polygon>")
+	}
+	if len(f.polygons) != 1 {
+		_, _ = e.w.WriteString("</MultiGeometry>")
+	}
+	_, _ = e.w.WriteString("</Placemark>\n")
+}
+
+func (e *kmlEncoder) ring(loop []LatLon) {
+	_, _ = e.w.WriteString("<LinearRing><coordinates>")
+	for i, ll := range closeLoop(loop) {
+		if i > 0 {
+			_ = e.w.WriteByte(' ')
+		}
+		writeFloat(e.w, ll.Lon)
+		_ = e.w.WriteByte(',')
+		writeFloat(e.w, ll.Lat)
+	}
+	_, _ = e.w.WriteString("</coordinates></LinearRing>")
+}
+
+func (e *kmlEncoder) end() {
+	_, _ = e.w.WriteString("</Document></kml>\n")
+}
+
+func (e *kmlEncoder) flush() error {
+	return e.w.Flush()
+}
+
+// closeLoop returns the loop with the first vertex repeated at the end.
+func closeLoop(loop []LatLon) []LatLon {
+	if len(loop) == 0 {
+		return loop
+	}
+	closed := make([]LatLon, 0, len(loop)+1)
+	closed = append(closed, loop...)
+	return append(closed, loop[0])
+}
+
+func writeFloat(w *bufio.Writer, v float64) {
+	var buf [32]byte
+	_, _ = w.Write(strconv.AppendFloat(buf[:0], v, 'f', -1, 64))
+}
+
+func writeJSON(w *bufio.Writer, v interface{}) {
+	b, _ := json.Marshal(v)
+	_, _ = w.Write(b)
+}
+
+func writeCSV(w *bufio.Writer, v interface{}) {
+	switch val := v.(type) {
+	case string:
+		_ = w.WriteByte('"')
+		for i := 0; i < len(val); i++ {
+			if val[i] == '"' {
+				_ = w.WriteByte('"')
+			}
+			_ = w.WriteByte(val[i])
+		}
+		_ = w.WriteByte('"')
+	default:
+		_, _ = fmt.Fprint(w, val)
+	}
+}
+
+func writeXML(w *bufio.Writer, s string) {
+	_ = xml.EscapeText(w, []byte(s))
+}
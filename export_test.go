@@ -0,0 +1,235 @@
+package h3geodist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_ExportCells(t *testing.T) {
+	h3dist, err := New(Level1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = h3dist.Add("127.0.0.1")
+	_ = h3dist.Add("127.0.0.2")
+
+	var buf bytes.Buffer
+	if err := h3dist.ExportCells(&buf, FormatGeoJSON); err != nil {
+		t.Fatal(err)
+	}
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Properties struct {
+				H3    string `json:"h3"`
+				Host  string `json:"host"`
+				VNode int    `json:"vnode"`
+			} `json:"properties"`
+			Geometry struct {
+				Type        string         `json:"type"`
+				Coordinates [][][2]float64 `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := uint(len(collection.Features)), NumCells(Level1); have != want {
+		t.Fatalf("have %d, want %d features", have, want)
+	}
+	for _, f := range collection.Features {
+		dcell, _ := h3dist.Lookup(h3.FromString(f.Properties.H3))
+		if have, want := f.Properties.Host, dcell.Host; have != want {
+			t.Fatalf("have %s, want %s", have, want)
+		}
+		if have, want := f.Properties.VNode, h3dist.VNodeIndex(dcell.H3ID); have != want {
+			t.Fatalf("have %d, want %d", have, want)
+		}
+		ring := f.Geometry.Coordinates[0]
+		if ring[0] != ring[len(ring)-1] {
+			t.Fatalf("ring is not closed")
+		}
+	}
+
+	buf.Reset()
+	if err := h3dist.ExportCells(&buf, FormatNDJSON); err != nil {
+		t.Fatal(err)
+	}
+	var lines uint
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if !json.Valid(scanner.Bytes()) {
+			t.Fatalf("invalid json %s", scanner.Text())
+		}
+		lines++
+	}
+	if have, want := lines, NumCells(Level1); have != want {
+		t.Fatalf("have %d, want %d lines", have, want)
+	}
+
+	buf.Reset()
+	if err := h3dist.ExportCells(&buf, FormatWKT); err != nil {
+		t.Fatal(err)
+	}
+	rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if have, want := rows[0], "h3,host,vnode,wkt"; have != want {
+		t.Fatalf("have %s, want %s header", have, want)
+	}
+	if have, want := uint(len(rows)-1), NumCells(Level1); have != want {
+		t.Fatalf("have %d, want %d rows", have, want)
+	}
+	if !strings.Contains(rows[1], `"POLYGON ((`) {
+		t.Fatalf("have %s, want POLYGON", rows[1])
+	}
+
+	buf.Reset()
+	if err := h3dist.ExportCells(&buf, FormatKML); err != nil {
+		t.Fatal(err)
+	}
+	var kml struct {
+		Placemarks []struct {
+			Name string `xml:"name"`
+		} `xml:"Document>Placemark"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &kml); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := uint(len(kml.Placemarks)), NumCells(Level1); have != want {
+		t.Fatalf("have %d, want %d placemarks", have, want)
+	}
+}
+
+func TestDistributed_ExportHosts(t *testing.T) {
+	h3dist, err := New(Level2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"}
+	for _, host := range hosts {
+		_ = h3dist.Add(host)
+	}
+	for _, format := range []Format{FormatGeoJSON, FormatNDJSON, FormatWKT, FormatKML} {
+		var buf bytes.Buffer
+		if err := h3dist.ExportHosts(&buf, format); err != nil {
+			t.Fatal(err)
+		}
+		for _, host := range hosts {
+			if !strings.Contains(buf.String(), host) {
+				t.Fatalf("%v: host %s not found", format, host)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := h3dist.ExportHosts(&buf, FormatGeoJSON); err != nil {
+		t.Fatal(err)
+	}
+	var collection struct {
+		Features []struct {
+			Geometry struct {
+				Type string `json:"type"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(collection.Features), len(hosts); have != want {
+		t.Fatalf("have %d, want %d features", have, want)
+	}
+}
+
+func TestDistributed_ExportWithError(t *testing.T) {
+	h3dist, _ := New(Level0)
+	_ = h3dist.Add("127.0.0.1")
+	if err := h3dist.ExportCells(&bytes.Buffer{}, Format(42)); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	want := errors.New("broken pipe")
+	if err := h3dist.ExportCells(failWriter{err: want}, FormatGeoJSON); !errors.Is(err, want) {
+		t.Fatalf("have %v, want %v error", err, want)
+	}
+}
+
+func TestDistributed_ExportCellsWithoutLock(t *testing.T) {
+	h3dist, _ := New(Level2)
+	_ = h3dist.Add("127.0.0.1")
+	done := make(chan error, 1)
+	go func() {
+		// the writer changes the topology while cells are exported
+		w := funcWriter(func(p []byte) (int, error) {
+			if err := h3dist.Add("127.0.0.2"); err != nil {
+				return 0, err
+			}
+			return len(p), nil
+		})
+		done <- h3dist.ExportCells(w, FormatGeoJSON)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("export blocks changes of the topology")
+	}
+}
+
+func TestDistributed_ExportHostsFromSnapshot(t *testing.T) {
+	h3dist, _ := New(Level2)
+	for i := 1; i <= 3; i++ {
+		_ = h3dist.Add(fmt.Sprintf("127.0.0.%d", i))
+	}
+	var buf bytes.Buffer
+	next := 4
+	// the writer changes the topology while hosts are exported
+	w := funcWriter(func(p []byte) (int, error) {
+		_ = h3dist.Add(fmt.Sprintf("127.0.0.%d", next))
+		next++
+		return buf.Write(p)
+	})
+	if err := h3dist.ExportHosts(w, FormatNDJSON); err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		var f struct {
+			Properties struct {
+				Cells int `json:"cells"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			t.Fatal(err)
+		}
+		total += f.Properties.Cells
+	}
+	if have, want := uint(total), NumCells(Level2); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+}
+
+type funcWriter func(p []byte) (int, error)
+
+func (w funcWriter) Write(p []byte) (int, error) {
+	return w(p)
+}
+
+type failWriter struct {
+	err error
+}
+
+func (w failWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
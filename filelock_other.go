@@ -0,0 +1,16 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+
+package h3geodist
+
+import (
+	"errors"
+	"os"
+)
+
+func tryLockFile(*os.File) (bool, error) {
+	return false, errors.New("h3geodist: file lock is not supported on the platform")
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
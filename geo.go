@@ -0,0 +1,162 @@
+package h3geodist
+
+import (
+	"math"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// earthRadiusM is the authalic radius of the Earth used by H3 (meters).
+const earthRadiusM = 6371007.180918475
+
+// LatLon is a type to represent a geographic coordinate in degrees.
+type LatLon struct {
+	Lat float64
+	Lon float64
+}
+
+func (ll LatLon) toGeo() h3.GeoCoord {
+	return h3.GeoCoord{Latitude: ll.Lat, Longitude: ll.Lon}
+}
+
+func latLonFromGeo(g h3.GeoCoord) LatLon {
+	return LatLon{Lat: g.Latitude, Lon: g.Longitude}
+}
+
+// distanceM returns the great circle distance between a and b in meters.
+func distanceM(a, b LatLon) float64 {
+	return h3.PointDistM(a.toGeo(), b.toGeo())
+}
+
+// intermediate returns the point at fraction f of the great circle arc from a to b.
+func intermediate(a, b LatLon, f float64) LatLon {
+	lat1, lon1 := toRad(a.Lat), toRad(a.Lon)
+	lat2, lon2 := toRad(b.Lat), toRad(b.Lon)
+	delta := h3.PointDistRads(a.toGeo(), b.toGeo())
+	sinDelta := math.Sin(delta)
+	if sinDelta < 1e-12 {
+		return a
+	}
+	ka := math.Sin((1-f)*delta) / sinDelta
+	kb := math.Sin(f*delta) / sinDelta
+	x := ka*math.Cos(lat1)*math.Cos(lon1) + kb*math.Cos(lat2)*math.Cos(lon2)
+	y := ka*math.Cos(lat1)*math.Sin(lon1) + kb*math.Cos(lat2)*math.Sin(lon2)
+	z := ka*math.Sin(lat1) + kb*math.Sin(lat2)
+	return LatLon{
+		Lat: toDeg(math.Atan2(z, math.Sqrt(x*x+y*y))),
+		Lon: toDeg(math.Atan2(y, x)),
+	}
+}
+
+func toRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func toDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// destination returns the point reached from ll after travelling
+// distM meters along the great circle with the initial bearing (degrees).
+func destination(ll LatLon, bearing float64, distM float64) LatLon {
+	lat1, lon1 := toRad(ll.Lat), toRad(ll.Lon)
+	theta := toRad(bearing)
+	delta := distM / earthRadiusM
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(delta) +
+		math.Cos(lat1)*math.Sin(delta)*math.Cos(theta))
+	lon2 := lon1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(lat1),
+		math.Cos(delta)-math.Sin(lat1)*math.Sin(lat2))
+	return LatLon{
+		Lat: toDeg(lat2),
+		Lon: math.Mod(toDeg(lon2)+540, 360) - 180,
+	}
+}
+
+// nearestOnBoundary returns the nearest point to p on the edges
+// of the cell boundary and the distance to it in meters.
+func nearestOnBoundary(p LatLon, boundary h3.GeoBoundary) (nearest LatLon, distM float64) {
+	distM = math.Inf(1)
+	for i := 0; i < len(boundary); i++ {
+		a := latLonFromGeo(boundary[i])
+		b := latLonFromGeo(boundary[(i+1)%len(boundary)])
+		point, dist := nearestOnArc(p, a, b)
+		if dist < distM {
+			nearest, distM = point, dist
+		}
+	}
+	return
+}
+
+// nearestOnArc returns the nearest point to p on the great circle arc
+// from a to b and the distance to it in meters.
+func nearestOnArc(p, a, b LatLon) (LatLon, float64) {
+	vp, va, vb := toVec(p), toVec(a), toVec(b)
+	n := va.cross(vb)
+	if n.norm() > 1e-15 {
+		n = n.scale(1 / n.norm())
+		proj := vp.sub(n.scale(vp.dot(n)))
+		if proj.norm() > 1e-15 {
+			proj = proj.scale(1 / proj.norm())
+			if va.cross(proj).dot(n) >= 0 && proj.cross(vb).dot(n) >= 0 {
+				return fromVec(proj), vp.angle(proj) * earthRadiusM
+			}
+		}
+	}
+	da, db := vp.angle(va), vp.angle(vb)
+	if da <= db {
+		return a, da * earthRadiusM
+	}
+	return b, db * earthRadiusM
+}
+
+// vec3 is a point on the unit sphere in cartesian coordinates.
+type vec3 [3]float64
+
+func toVec(ll LatLon) vec3 {
+	lat, lon := toRad(ll.Lat), toRad(ll.Lon)
+	return vec3{
+		math.Cos(lat) * math.Cos(lon),
+		math.Cos(lat) * math.Sin(lon),
+		math.Sin(lat),
+	}
+}
+
+func fromVec(v vec3) LatLon {
+	return LatLon{
+		Lat: toDeg(math.Atan2(v[2], math.Hypot(v[0], v[1]))),
+		Lon: toDeg(math.Atan2(v[1], v[0])),
+	}
+}
+
+func (v vec3) dot(u vec3) float64 {
+	return v[0]*u[0] + v[1]*u[1] + v[2]*u[2]
+}
+
+func (v vec3) cross(u vec3) vec3 {
+	return vec3{
+		v[1]*u[2] - v[2]*u[1],
+		v[2]*u[0] - v[0]*u[2],
+		v[0]*u[1] - v[1]*u[0],
+	}
+}
+
+func (v vec3) add(u vec3) vec3 {
+	return vec3{v[0] + u[0], v[1] + u[1], v[2] + u[2]}
+}
+
+func (v vec3) sub(u vec3) vec3 {
+	return vec3{v[0] - u[0], v[1] - u[1], v[2] - u[2]}
+}
+
+func (v vec3) scale(k float64) vec3 {
+	return vec3{v[0] * k, v[1] * k, v[2] * k}
+}
+
+func (v vec3) norm() float64 {
+	return math.Sqrt(v.dot(v))
+}
+
+// angle returns the angle between unit vectors in radians.
+func (v vec3) angle(u vec3) float64 {
+	return math.Atan2(v.cross(u).norm(), v.dot(u))
+}
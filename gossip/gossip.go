@@ -0,0 +1,258 @@
+// Package gossip provides the membership of nodes
+// by the SWIM-style gossip protocol on top of hashicorp/memberlist,
+// for clusters without a central registry.
+//
+// Gossip implements h3geodist.MembershipSource, so that a Distributed
+// follows join, leave and failure events:
+//
+//	g, err := gossip.New(gossip.Config{Name: "node-1", Meta: gossip.Meta{Addr: "10.0.0.1:8080"}})
+//	...
+//	_, err = g.Join([]string{"10.0.0.2:7946"})
+//	...
+//	go h3dist.Follow(ctx, g, time.Second)
+package gossip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+)
+
+// Meta is a type to represent the node metadata carried in the gossip payload.
+type Meta struct {
+	// Addr is the address of the node in the distribution,
+	// the name of the gossip member if empty.
+	Addr   string            `json:"addr,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Weight float64           `json:"weight,omitempty"`
+}
+
+// Config is a type to represent the gossip member configuration.
+type Config struct {
+	// Name is the unique name of the member, the hostname if empty.
+	Name string
+	// BindAddr and BindPort are the address of the gossip protocol.
+	// Port 0 picks a free port.
+	BindAddr string
+	BindPort int
+	Meta     Meta
+	// Memberlist is the base configuration of memberlist,
+	// memberlist.DefaultLANConfig if nil.
+	Memberlist *memberlist.Config
+	// Logger is the logger of memberlist, logs are discarded if nil.
+	Logger *log.Logger
+}
+
+// Gossip is a member of the gossip cluster.
+// Thread-safe.
+type Gossip struct {
+	list     *memberlist.Memberlist
+	mu       sync.Mutex
+	meta     []byte
+	members  map[string]Meta
+	watchers map[chan struct{}]struct{}
+}
+
+// New creates, starts and returns a new gossip member.
+func New(conf Config) (*Gossip, error) {
+	meta, err := json.Marshal(conf.Meta)
+	if err != nil {
+		return nil, err
+	}
+	if len(meta) > memberlist.MetaMaxSize {
+		return nil, fmt.Errorf("gossip: meta size got %d, expected <= %d",
+			len(meta), memberlist.MetaMaxSize)
+	}
+	g := &Gossip{
+		meta:     meta,
+		members:  make(map[string]Meta),
+		watchers: make(map[chan struct{}]struct{}),
+	}
+	mconf := conf.Memberlist
+	if mconf == nil {
+		mconf = memberlist.DefaultLANConfig()
+	}
+	if conf.Name != "" {
+		mconf.Name = conf.Name
+	}
+	if conf.BindAddr != "" {
+		mconf.BindAddr = conf.BindAddr
+	}
+	mconf.BindPort = conf.BindPort
+	mconf.AdvertisePort = conf.BindPort
+	mconf.Delegate = delegate{g}
+	mconf.Events = events{g}
+	mconf.Logger = conf.Logger
+	if mconf.Logger == nil {
+		mconf.Logger = log.New(io.Discard, "", 0)
+	}
+	list, err := memberlist.Create(mconf)
+	if err != nil {
+		return nil, err
+	}
+	g.list = list
+	return g, nil
+}
+
+// Join joins the cluster by the addresses of existing members
+// and returns the number of contacted members.
+func (g *Gossip) Join(peers []string) (int, error) {
+	return g.list.Join(peers)
+}
+
+// Leave broadcasts the leave of the member and waits for the timeout.
+func (g *Gossip) Leave(timeout time.Duration) error {
+	return g.list.Leave(timeout)
+}
+
+// Shutdown stops the member without the leave broadcast,
+// so others see it as failed.
+func (g *Gossip) Shutdown() error {
+	return g.list.Shutdown()
+}
+
+// Addr returns the address of the gossip protocol.
+func (g *Gossip) Addr() string {
+	return g.list.LocalNode().Address()
+}
+
+// UpdateMeta changes the metadata of the member and broadcasts it.
+func (g *Gossip) UpdateMeta(meta Meta, timeout time.Duration) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.meta = data
+	g.mu.Unlock()
+	return g.list.UpdateNode(timeout)
+}
+
+// Members returns the metadata of alive members sorted by address.
+func (g *Gossip) Members() []Meta {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	members := make([]Meta, 0, len(g.members))
+	for _, meta := range g.members {
+		members = append(members, meta)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Addr < members[j].Addr
+	})
+	return members
+}
+
+// Meta returns the metadata of the member by the address.
+func (g *Gossip) Meta(addr string) (Meta, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, meta := range g.members {
+		if meta.Addr == addr {
+			return meta, true
+		}
+	}
+	return Meta{}, false
+}
+
+// Watch calls fn with the addresses of alive members
+// and on every join, leave, failure or update until the context is done.
+func (g *Gossip) Watch(ctx context.Context, fn func(members []string)) error {
+	changed := make(chan struct{}, 1)
+	g.mu.Lock()
+	g.watchers[changed] = struct{}{}
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		delete(g.watchers, changed)
+		g.mu.Unlock()
+	}()
+	var last []string
+	for {
+		members := g.addrs()
+		if last == nil || !equal(members, last) {
+			last = members
+			fn(members)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+func (g *Gossip) addrs() []string {
+	members := g.Members()
+	addrs := make([]string, 0, len(members))
+	for _, meta := range members {
+		addrs = append(addrs, meta.Addr)
+	}
+	return addrs
+}
+
+// update sets the member metadata or removes the member that is not alive.
+func (g *Gossip) update(node *memberlist.Node, alive bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !alive {
+		delete(g.members, node.Name)
+	} else {
+		var meta Meta
+		_ = json.Unmarshal(node.Meta, &meta)
+		if meta.Addr == "" {
+			meta.Addr = node.Name
+		}
+		g.members[node.Name] = meta
+	}
+	for changed := range g.watchers {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+type delegate struct {
+	g *Gossip
+}
+
+func (d delegate) NodeMeta(limit int) []byte {
+	d.g.mu.Lock()
+	defer d.g.mu.Unlock()
+	if len(d.g.meta) > limit {
+		return nil
+	}
+	return d.g.meta
+}
+
+func (d delegate) NotifyMsg([]byte)                           {}
+func (d delegate) GetBroadcasts(overhead, limit int) [][]byte { return nil }
+func (d delegate) LocalState(join bool) []byte                { return nil }
+func (d delegate) MergeRemoteState(buf []byte, join bool)     {}
+
+type events struct {
+	g *Gossip
+}
+
+func (e events) NotifyJoin(node *memberlist.Node)   { e.g.update(node, true) }
+func (e events) NotifyLeave(node *memberlist.Node)  { e.g.update(node, false) }
+func (e events) NotifyUpdate(node *memberlist.Node) { e.g.update(node, true) }
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package gossip
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	h3geodist "github.com/mmadfox/go-h3geo-dist"
+)
+
+func newMember(t *testing.T, name string, meta Meta) *Gossip {
+	t.Helper()
+	conf := memberlist.DefaultLocalConfig()
+	conf.ProbeInterval = 100 * time.Millisecond
+	conf.ProbeTimeout = 50 * time.Millisecond
+	conf.GossipInterval = 20 * time.Millisecond
+	conf.SuspicionMult = 1
+	g, err := New(Config{
+		Name:       name,
+		BindAddr:   "127.0.0.1",
+		Meta:       meta,
+		Memberlist: conf,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = g.Shutdown() })
+	return g
+}
+
+func waitNodes(t *testing.T, h3dist *h3geodist.Distributed, want []string) {
+	t.Helper()
+	deadline := time.Now().Add(20 * time.Second)
+	for {
+		have := h3dist.Nodes()
+		sort.Strings(have)
+		if reflect.DeepEqual(have, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("have %v, want %v", have, want)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestGossip_Follow(t *testing.T) {
+	g1 := newMember(t, "node-1", Meta{Addr: "10.0.0.1:8080", Labels: map[string]string{"zone": "a"}, Weight: 2})
+	g2 := newMember(t, "node-2", Meta{Addr: "10.0.0.2:8080"})
+	g3 := newMember(t, "node-3", Meta{})
+	for _, g := range []*Gossip{g2, g3} {
+		if _, err := g.Join([]string{g1.Addr()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h3dist, err := h3geodist.New(h3geodist.Level1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = h3dist.Follow(ctx, g2, 10*time.Millisecond)
+	}()
+	waitNodes(t, h3dist, []string{"10.0.0.1:8080", "10.0.0.2:8080", "node-3"})
+
+	meta, ok := g2.Meta("10.0.0.1:8080")
+	if !ok {
+		t.Fatalf("have false, want true")
+	}
+	if have, want := meta.Labels["zone"], "a"; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+	if have, want := meta.Weight, 2.0; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+
+	// leave
+	if err := g1.Leave(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	_ = g1.Shutdown()
+	waitNodes(t, h3dist, []string{"10.0.0.2:8080", "node-3"})
+
+	// failure
+	_ = g3.Shutdown()
+	waitNodes(t, h3dist, []string{"10.0.0.2:8080"})
+
+	// metadata update
+	if err := g2.UpdateMeta(Meta{Addr: "10.0.0.2:9090"}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	waitNodes(t, h3dist, []string{"10.0.0.2:9090"})
+}
@@ -0,0 +1,123 @@
+package grpcdist
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/mmadfox/go-h3geo-dist"
+	"github.com/mmadfox/go-h3geo-dist/proxy"
+	"github.com/uber/h3-go/v3"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Name is the name of the balancer.
+const Name = "h3geodist"
+
+// Metadata keys of the routing key of a call.
+const (
+	CellKey = "h3-cell"
+	LatKey  = "h3-lat"
+	LonKey  = "h3-lon"
+)
+
+func init() {
+	balancer.Register(NewBalancerBuilder())
+}
+
+// NewBalancerBuilder returns the balancer builder picking the connection
+// to the owner of the cell or the coordinate in the outgoing metadata.
+// Calls without a key are spread round-robin.
+// The balancer is registered under Name and expects
+// the addresses of the resolver of this package.
+func NewBalancerBuilder() balancer.Builder {
+	return base.NewBalancerBuilder(Name, pickerBuilder{}, base.Config{})
+}
+
+// WithCell returns the context routing the call to the owner of the cell.
+func WithCell(ctx context.Context, cell h3.H3Index) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, CellKey, h3.ToString(cell))
+}
+
+// WithLatLon returns the context routing the call to the owner of the coordinate.
+func WithLatLon(ctx context.Context, lat, lon float64) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		LatKey, strconv.FormatFloat(lat, 'f', -1, 64),
+		LonKey, strconv.FormatFloat(lon, 'f', -1, 64))
+}
+
+type pickerBuilder struct{}
+
+func (pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &picker{
+		hosts: make(map[string]balancer.SubConn, len(info.ReadySCs)),
+		conns: make([]balancer.SubConn, 0, len(info.ReadySCs)),
+	}
+	for sc, sci := range info.ReadySCs {
+		if d, ok := sci.Address.BalancerAttributes.Value(distKey{}).(*h3geodist.Distributed); ok {
+			p.dist = d
+		}
+		p.hosts[sci.Address.Addr] = sc
+		p.conns = append(p.conns, sc)
+	}
+	return p
+}
+
+type picker struct {
+	dist  *h3geodist.Distributed
+	hosts map[string]balancer.SubConn
+	conns []balancer.SubConn
+	next  uint32
+}
+
+// Pick returns the connection to the owner of the key.
+// Invalid keys and keys outside the coverage end the call with Unknown,
+// the restricted InvalidArgument and NotFound codes are not allowed
+// for pickers. Calls wait while the owner is not ready.
+func (p *picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	key, err := outgoingKey(info.Ctx)
+	if errors.Is(err, proxy.ErrNoKey) || p.dist == nil {
+		n := atomic.AddUint32(&p.next, 1)
+		return balancer.PickResult{SubConn: p.conns[n%uint32(len(p.conns))]}, nil
+	}
+	if err != nil {
+		return balancer.PickResult{}, status.Error(codes.Unknown, err.Error())
+	}
+	c, err := proxy.Lookup(p.dist, key)
+	switch {
+	case errors.Is(err, proxy.ErrCellLevel), errors.Is(err, h3geodist.ErrOutOfCoverage):
+		return balancer.PickResult{}, status.Error(codes.Unknown, err.Error())
+	case err != nil:
+		// no nodes yet, wait-for-ready calls wait for the next picker
+		return balancer.PickResult{}, err
+	}
+	sc, ok := p.hosts[c.Host]
+	if !ok {
+		return balancer.PickResult{}, balancer.ErrNoSubConnAvailable
+	}
+	return balancer.PickResult{SubConn: sc}, nil
+}
+
+// outgoingKey returns the routing key of the outgoing metadata.
+func outgoingKey(ctx context.Context) (proxy.Key, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	get := func(name string) string {
+		if values := md.Get(name); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	key, err := proxy.ParseCell(get(CellKey))
+	if !errors.Is(err, proxy.ErrNoKey) {
+		return key, err
+	}
+	return proxy.ParseLatLon(get(LatKey), get(LonKey))
+}
@@ -0,0 +1,181 @@
+package grpcdist
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mmadfox/go-h3geo-dist"
+	"github.com/uber/h3-go/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newBackend(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("backend", addr))
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return addr
+}
+
+// backendOf calls the backend with the context and returns its address.
+func backendOf(t *testing.T, client healthpb.HealthClient, ctx context.Context) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var md metadata.MD
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&md), grpc.WaitForReady(true)); err != nil {
+		t.Fatal(err)
+	}
+	return md.Get("backend")[0]
+}
+
+func dial(t *testing.T, h3dist *h3geodist.Distributed) healthpb.HealthClient {
+	conn, err := grpc.Dial(Scheme+":///health",
+		grpc.WithResolvers(NewResolverBuilder(h3dist)),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestBalancer(t *testing.T) {
+	h3dist, err := h3geodist.New(h3geodist.Level3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := h3dist.Add(newBackend(t)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := dial(t, h3dist)
+
+	owners := make(map[string]h3geodist.Cell)
+	h3dist.EachCell(func(c h3geodist.Cell) {
+		if _, ok := owners[c.Host]; !ok {
+			owners[c.Host] = c
+		}
+	})
+	for host, c := range owners {
+		if have, want := backendOf(t, client, WithCell(context.Background(), c.H3ID)), host; have != want {
+			t.Fatalf("have %s, want %s", have, want)
+		}
+	}
+	owner, err := h3dist.LookupFromLatLon(52.52, 13.405)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := backendOf(t, client, WithLatLon(context.Background(), 52.52, 13.405)), owner.Host; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+	_ = backendOf(t, client, context.Background())
+
+	// the removed host loses its cells
+	h3dist.Remove(owner.Host)
+	next, err := h3dist.LookupFromLatLon(52.52, 13.405)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		have := backendOf(t, client, WithLatLon(context.Background(), 52.52, 13.405))
+		if have == next.Host {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("have %s, want %s", have, next.Host)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBalancer_Codes(t *testing.T) {
+	covered := h3.FromGeo(h3.GeoCoord{Latitude: 52.52, Longitude: 13.405}, h3geodist.Level3)
+	h3dist, err := h3geodist.New(h3geodist.Level3, h3geodist.WithCoverage(covered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h3dist.Add(newBackend(t)); err != nil {
+		t.Fatal(err)
+	}
+	client := dial(t, h3dist)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// the call waits for the owner instead of failing right after dial
+	if _, err := client.Check(WithCell(ctx, covered), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Check(WithCell(ctx, h3.ToCenterChild(covered, 9)), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	uncovered := h3.FromGeo(h3.GeoCoord{Latitude: 35.6762, Longitude: 139.6503}, h3geodist.Level3)
+	calls := map[string]context.Context{
+		"out of coverage": WithCell(ctx, uncovered),
+		"coarse cell":     WithCell(ctx, h3.ToParent(covered, h3geodist.Level1)),
+		"invalid cell":    metadata.AppendToOutgoingContext(ctx, CellKey, "zzz"),
+	}
+	for name, callCtx := range calls {
+		_, err := client.Check(callCtx, &healthpb.HealthCheckRequest{})
+		if have, want := status.Code(err), codes.Unknown; have != want {
+			t.Fatalf("%s: have %v, want %v", name, have, want)
+		}
+	}
+}
+
+func TestBalancer_Draining(t *testing.T) {
+	h3dist, err := h3geodist.New(h3geodist.Level3, h3geodist.WithStepSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := h3dist.Add(newBackend(t)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	owner, err := h3dist.LookupFromLatLon(52.52, 13.405)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the removed host keeps its cells until they are moved by Step
+	h3dist.Remove(owner.Host)
+	client := dial(t, h3dist)
+	if have, want := backendOf(t, client, WithLatLon(context.Background(), 52.52, 13.405)), owner.Host; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+	for !h3dist.Progress().Done() {
+		h3dist.Step()
+	}
+	next, err := h3dist.LookupFromLatLon(52.52, 13.405)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		have := backendOf(t, client, WithLatLon(context.Background(), 52.52, 13.405))
+		if have == next.Host {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("have %s, want %s", have, next.Host)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
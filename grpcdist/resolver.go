@@ -0,0 +1,96 @@
+// Package grpcdist provides the gRPC resolver and balancer
+// sending each call to the host owning the cell of the call,
+// so clients talk to the right shard without a proxy hop:
+//
+//	conn, err := grpc.Dial("h3geodist:///objects",
+//		grpc.WithResolvers(grpcdist.NewResolverBuilder(h3dist)),
+//		grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	...
+//	ctx = grpcdist.WithCell(ctx, cell)
+//	resp, err := client.Get(ctx, req)
+//
+// The resolver keeps the connections in sync with the nodes of the Distributed
+// and the owners of its vnodes, and selects the balancer registered by this package.
+package grpcdist
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mmadfox/go-h3geo-dist"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+// Scheme is the target scheme of the resolver.
+const Scheme = "h3geodist"
+
+// distKey is the key of the Distributed in the address attributes.
+type distKey struct{}
+
+type resolverBuilder struct {
+	dist *h3geodist.Distributed
+}
+
+// NewResolverBuilder returns the resolver builder of the addresses
+// of the distribution nodes, see grpc.WithResolvers.
+func NewResolverBuilder(d *h3geodist.Distributed) resolver.Builder {
+	return resolverBuilder{dist: d}
+}
+
+func (b resolverBuilder) Scheme() string {
+	return Scheme
+}
+
+func (b resolverBuilder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	sc := cc.ParseServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, Name))
+	if sc.Err != nil {
+		return nil, sc.Err
+	}
+	r := &distResolver{dist: b.dist, cc: cc, sc: sc}
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	r.stop = b.dist.Watch(func(e h3geodist.Event) {
+		if len(e.Added) > 0 || len(e.Removed) > 0 || len(e.Moves) > 0 {
+			r.ResolveNow(resolver.ResolveNowOptions{})
+		}
+	})
+	return r, nil
+}
+
+type distResolver struct {
+	dist *h3geodist.Distributed
+	cc   resolver.ClientConn
+	sc   *serviceconfig.ParseResult
+	mu   sync.Mutex
+	stop func()
+}
+
+// ResolveNow pushes the current nodes to the client connection,
+// along with removed hosts that still own vnodes while draining.
+func (r *distResolver) ResolveNow(resolver.ResolveNowOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	nodes := r.dist.Nodes()
+	seen := make(map[string]struct{}, len(nodes))
+	for _, addr := range nodes {
+		seen[addr] = struct{}{}
+	}
+	r.dist.EachVNode(func(_ uint64, addr string) bool {
+		if _, ok := seen[addr]; !ok {
+			seen[addr] = struct{}{}
+			nodes = append(nodes, addr)
+		}
+		return true
+	})
+	attrs := attributes.New(distKey{}, r.dist)
+	addrs := make([]resolver.Address, 0, len(nodes))
+	for _, addr := range nodes {
+		addrs = append(addrs, resolver.Address{Addr: addr, BalancerAttributes: attrs})
+	}
+	_ = r.cc.UpdateState(resolver.State{Addresses: addrs, ServiceConfig: r.sc})
+}
+
+func (r *distResolver) Close() {
+	r.stop()
+}
@@ -0,0 +1,91 @@
+package h3geodist
+
+import (
+	"math"
+	"time"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// handoffHorizon is the number of cell edges ahead of the point
+// within which PredictHandoff searches for a host boundary.
+const handoffHorizon = 128
+
+// maxArcM limits the horizon to less than a half of the great circle.
+const maxArcM = 0.9 * math.Pi * earthRadiusM
+
+// Handoff is a type to represent a predicted transition
+// of a moving object from the territory of one host to another.
+type Handoff struct {
+	// Current is the distributed cell of the object.
+	Current Cell
+	// From is the last cell of the current host along the heading.
+	From Cell
+	// To is the first cell of the next host along the heading,
+	// its host is empty when the object leaves the coverage.
+	To Cell
+	// Exit is the point where the heading crosses the host boundary.
+	Exit LatLon
+	// Edge holds the vertices of the boundary edge between From and To.
+	Edge []LatLon
+	// DistanceM is the distance from the object to the exit point.
+	DistanceM float64
+	// Duration is the estimated time until the exit point.
+	Duration time.Duration
+}
+
+// PredictHandoff returns the next host along the heading of an object
+// moving from a geographic coordinate with the bearing (degrees clockwise from north)
+// and the speed (meters per second).
+// The object is assumed to move along a great circle.
+func (d *Distributed) PredictHandoff(lat float64, lon float64, bearing float64, speed float64) (h Handoff, err error) {
+	if speed <= 0 || math.IsNaN(speed) {
+		return h, ErrSpeed
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	origin := LatLon{Lat: lat, Lon: lon}
+	cell, addr, ok := d.locate(origin)
+	if !ok {
+		return h, ErrVNodes
+	}
+	if addr == "" {
+		return h, ErrOutOfCoverage
+	}
+	h.Current = Cell{H3ID: cell, Host: addr}
+	edgeLength := h3.EdgeLengthM(d.level)
+	horizon := math.Min(handoffHorizon*edgeLength, maxArcM)
+	target := destination(origin, bearing, horizon)
+	steps := int(math.Ceil(horizon / d.sampleStepM()))
+	for j := 1; j <= steps; j++ {
+		f := float64(j) / float64(steps)
+		_, next, _ := d.locate(intermediate(origin, target, f))
+		if next == addr {
+			continue
+		}
+		lo, hi := d.crossing(origin, target, horizon, float64(j-1)/float64(steps), f, addr)
+		fromCell, _, _ := d.locate(intermediate(origin, target, lo))
+		toCell, toAddr, _ := d.locate(intermediate(origin, target, hi))
+		h.From = Cell{H3ID: fromCell, Host: addr}
+		h.To = Cell{H3ID: toCell, Host: toAddr}
+		h.Exit = intermediate(origin, target, (lo+hi)/2)
+		h.Edge = edgeBetween(fromCell, toCell)
+		h.DistanceM = horizon * (lo + hi) / 2
+		h.Duration = time.Duration(h.DistanceM / speed * float64(time.Second))
+		return h, nil
+	}
+	return h, ErrNoHandoff
+}
+
+// edgeBetween returns the vertices of the edge shared by neighbor cells.
+func edgeBetween(origin, destination h3.H3Index) []LatLon {
+	if !h3.AreNeighbors(origin, destination) {
+		return nil
+	}
+	boundary := h3.UnidirectionalEdgeBoundary(h3.UnidirectionalEdge(origin, destination))
+	edge := make([]LatLon, 0, len(boundary))
+	for i := 0; i < len(boundary); i++ {
+		edge = append(edge, latLonFromGeo(boundary[i]))
+	}
+	return edge
+}
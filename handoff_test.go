@@ -0,0 +1,74 @@
+package h3geodist
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDistributed_PredictHandoff(t *testing.T) {
+	h3dist, err := New(Level6, WithVNodes(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = h3dist.Add("127.0.0.1")
+	_ = h3dist.Add("127.0.0.2")
+	_ = h3dist.Add("127.0.0.3")
+
+	lat, lon := 42.9325219, -72.2822266
+	speed := 25.0
+	for _, bearing := range []float64{0, 45, 90, 180, 270} {
+		h, err := h3dist.PredictHandoff(lat, lon, bearing, speed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := h.From.Host, h.Current.Host; have != want {
+			t.Fatalf("have %s, want %s", have, want)
+		}
+		if h.To.Host == h.Current.Host {
+			t.Fatalf("have %s, want another host", h.To.Host)
+		}
+		if have, want := len(h.Edge), 2; have != want {
+			t.Fatalf("have %d, want %d edge vertices", have, want)
+		}
+		want := time.Duration(h.DistanceM / speed * float64(time.Second))
+		if have := h.Duration; have != want {
+			t.Fatalf("have %v, want %v", have, want)
+		}
+		origin := LatLon{Lat: lat, Lon: lon}
+		if have, want := distanceM(origin, h.Exit), h.DistanceM; math.Abs(have-want) > 1 {
+			t.Fatalf("have %f, want %f distance to exit", have, want)
+		}
+		before := destination(origin, bearing, h.DistanceM-boundaryPrecisionM)
+		dcell, err := h3dist.LookupFromLatLon(before.Lat, before.Lon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := dcell.Host, h.Current.Host; have != want {
+			t.Fatalf("have %s, want %s before exit", have, want)
+		}
+		after := destination(origin, bearing, h.DistanceM+boundaryPrecisionM)
+		dcell, err = h3dist.LookupFromLatLon(after.Lat, after.Lon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := dcell.Host, h.To.Host; have != want {
+			t.Fatalf("have %s, want %s after exit", have, want)
+		}
+	}
+}
+
+func TestDistributed_PredictHandoffWithError(t *testing.T) {
+	h3dist, _ := New(Level3)
+	if _, err := h3dist.PredictHandoff(42.9325219, -72.2822266, 90, 10); !errors.Is(err, ErrVNodes) {
+		t.Fatalf("have %v, want %v error", err, ErrVNodes)
+	}
+	_ = h3dist.Add("127.0.0.1")
+	if _, err := h3dist.PredictHandoff(42.9325219, -72.2822266, 90, 0); !errors.Is(err, ErrSpeed) {
+		t.Fatalf("have %v, want %v error", err, ErrSpeed)
+	}
+	if _, err := h3dist.PredictHandoff(42.9325219, -72.2822266, 90, 10); !errors.Is(err, ErrNoHandoff) {
+		t.Fatalf("have %v, want %v error", err, ErrNoHandoff)
+	}
+}
@@ -0,0 +1,159 @@
+package h3geodist
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// MaxSplitDepth is the maximum number of levels a hot cell is split
+// below the distribution level, so a hot cell has at most 7^MaxSplitDepth shards.
+const MaxSplitDepth = 3
+
+// MarkHot marks the cell at the distribution level as hot.
+// The children of a hot cell at the finer level are distributed independently,
+// so the traffic of the cell is spread over several nodes.
+// Lookups resolve to the children (shards) transparently.
+func (d *Distributed) MarkHot(cell h3.H3Index, level int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.publish(d.state())
+	if err := d.markHot(cell, level); err != nil {
+		return err
+	}
+	return d.rebalanceHot()
+}
+
+// UnmarkHot merges the children of the hot cell back into the cell,
+// a cell within a region gets back the level of the region.
+func (d *Distributed) UnmarkHot(cell h3.H3Index) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.publish(d.state())
+	split, ok := d.hot[cell]
+	if !ok || split == d.regions[cell] {
+		return nil
+	}
+	if level, ok := d.regions[cell]; ok {
+		d.hot[cell] = level
+	} else {
+		delete(d.hot, cell)
+	}
+	return d.rebalanceHot()
+}
+
+// MarkHotByLoad marks the cells whose measured load exceeds the capacity of a node as hot.
+// Each cell is split to the level at which the load of a child,
+// assuming an even spread over 7 children per level, fits the capacity,
+// but at most MaxSplitDepth levels below the distribution level.
+// It returns the marked cells sorted by index.
+func (d *Distributed) MarkHotByLoad(load map[h3.H3Index]float64, capacity float64) ([]h3.H3Index, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("h3geodist: capacity got %f, expected > 0", capacity)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.publish(d.state())
+	marked := make([]h3.H3Index, 0, 4)
+	for cell, value := range load {
+		if value <= capacity || h3.Resolution(cell) != d.level {
+			continue
+		}
+		level := d.level + int(math.Ceil(math.Log(value/capacity)/math.Log(7)))
+		if max := d.maxSplitLevel(); level > max {
+			level = max
+		}
+		if split, ok := d.hot[cell]; ok && split >= level {
+			continue
+		}
+		if err := d.markHot(cell, level); err != nil {
+			return nil, err
+		}
+		marked = append(marked, cell)
+	}
+	sort.Slice(marked, func(i, j int) bool {
+		return marked[i] < marked[j]
+	})
+	if len(marked) == 0 {
+		return marked, nil
+	}
+	return marked, d.rebalanceHot()
+}
+
+// HotCells returns the hot cells with their split levels.
+func (d *Distributed) HotCells() map[h3.H3Index]int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	cells := make(map[h3.H3Index]int, len(d.hot))
+	for cell, level := range d.hot {
+		cells[cell] = level
+	}
+	return cells
+}
+
+func (d *Distributed) markHot(cell h3.H3Index, level int) error {
+	if res := h3.Resolution(cell); res != d.level || !h3.IsValid(cell) {
+		return fmt.Errorf("h3geodist: hot cell resolution got %d, expected %d", res, d.level)
+	}
+	if err := d.validateSplitLevel(level); err != nil {
+		return err
+	}
+	if d.hot == nil {
+		d.hot = make(map[h3.H3Index]int)
+	}
+	d.hot[cell] = level
+	return nil
+}
+
+// maxSplitLevel returns the finest split level of hot cells.
+func (d *Distributed) maxSplitLevel() int {
+	if max := d.level + MaxSplitDepth; max < h3.MaxResolution {
+		return max
+	}
+	return h3.MaxResolution
+}
+
+func (d *Distributed) validateSplitLevel(level int) error {
+	if level <= d.level || level > d.maxSplitLevel() {
+		return fmt.Errorf("h3geodist: hot cell level got %d, expected [%d-%d]",
+			level, d.level+1, d.maxSplitLevel())
+	}
+	return nil
+}
+
+// rebalanceHot recomputes the weights of vnodes after the change of hot cells.
+func (d *Distributed) rebalanceHot() error {
+	if d.weights == nil {
+		return nil
+	}
+	d.updateWeights()
+	if len(d.nodes) == 0 {
+		return nil
+	}
+	return d.distribute()
+}
+
+// shards returns the shards of the cell at the distribution level,
+// or the cell itself if it is not hot.
+func (d *Distributed) shards(cell h3.H3Index) []h3.H3Index {
+	split, ok := d.hot[cell]
+	if !ok {
+		return []h3.H3Index{cell}
+	}
+	return h3.ToChildren(cell, split)
+}
+
+// withinHot returns TRUE if the cell is finer than the level
+// and coarser than the split level of its hot parent.
+func (d *Distributed) withinHot(cell h3.H3Index) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	res := h3.Resolution(cell)
+	if res <= d.level {
+		return false
+	}
+	split, ok := d.hot[h3.ToParent(cell, d.level)]
+	return ok && res < split
+}
@@ -0,0 +1,165 @@
+package h3geodist
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_MarkHot(t *testing.T) {
+	h3dist, err := New(Level3, WithVNodes(512))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		_ = h3dist.Add(fmt.Sprintf("host-%d.com", i))
+	}
+	city := LatLon{Lat: 40.7128, Lon: -74.0060}
+	cell := h3.FromGeo(city.toGeo(), Level3)
+	if err := h3dist.MarkHot(cell, Level3); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	if err := h3dist.MarkHot(h3.ToCenterChild(cell, Level5), Level5); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	if err := h3dist.MarkHot(cell, Level5); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := h3dist.HotCells()[cell], Level5; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	// lookups resolve to the shard
+	c, err := h3dist.LookupFromLatLon(city.Lat, city.Lon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := c.H3ID, h3.FromGeo(city.toGeo(), Level5); have != want {
+		t.Fatalf("have %v, want %v", h3.ToString(have), h3.ToString(want))
+	}
+	if !h3dist.IsOwned(c) {
+		t.Fatalf("h3dist.IsOwned(%v) => false, expected true", c)
+	}
+	other, ok := h3dist.Lookup(h3.FromGeo(city.toGeo(), Level6+2))
+	if !ok || other != c {
+		t.Fatalf("have %v, want %v", other, c)
+	}
+	parent, err := h3dist.WhereIsMyParent(h3.FromGeo(city.toGeo(), 9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent != c {
+		t.Fatalf("have %v, want %v", parent, c)
+	}
+	if _, err := h3dist.WhereIsMyParent(h3.FromGeo(city.toGeo(), Level4)); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+
+	// shards are distributed independently
+	hosts := make(map[string]struct{})
+	for _, child := range h3.ToChildren(cell, Level5) {
+		c, ok := h3dist.Lookup(child)
+		if !ok {
+			t.Fatalf("have false, want true")
+		}
+		hosts[c.Host] = struct{}{}
+	}
+	if len(hosts) < 2 {
+		t.Fatalf("have %d, want > 1 hosts", len(hosts))
+	}
+	var cells uint
+	h3dist.EachCell(func(c Cell) {
+		cells++
+	})
+	if have, want := cells, NumCells(Level3)-1+49; have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+
+	if err := h3dist.UnmarkHot(cell); err != nil {
+		t.Fatal(err)
+	}
+	c, err = h3dist.LookupFromLatLon(city.Lat, city.Lon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := c.H3ID, cell; have != want {
+		t.Fatalf("have %v, want %v", h3.ToString(have), h3.ToString(want))
+	}
+}
+
+func TestDistributed_MarkHotByLoad(t *testing.T) {
+	city := h3.FromGeo(h3.GeoCoord{Latitude: 51.5074, Longitude: -0.1278}, Level3)
+	weight := func(cell h3.H3Index) float64 {
+		if cell == city {
+			return 1000
+		}
+		return 1
+	}
+	h3dist, err := New(Level3, WithVNodes(512), WithCellWeight(weight))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		_ = h3dist.Add(fmt.Sprintf("host-%d.com", i))
+	}
+	if _, err := h3dist.MarkHotByLoad(nil, 0); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	load := map[h3.H3Index]float64{
+		city:                      1000,
+		h3.ToCenterChild(city, 4): 5000,
+		h3.KRing(city, 1)[1]:      10,
+	}
+	marked, err := h3dist.MarkHotByLoad(load, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(marked), 1; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	// 1000/100 needs two levels of 7 children
+	if have, want := h3dist.HotCells()[city], Level5; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	var total float64
+	for vnode := uint64(0); vnode < h3dist.VNodes(); vnode++ {
+		total += h3dist.VNodeWeight(vnode)
+	}
+	var want float64
+	Iter(Level3, func(_ uint, cell h3.H3Index) {
+		want += weight(cell)
+	})
+	if diff := total - want; diff > 1e-6 || diff < -1e-6 {
+		t.Fatalf("have %f, want %f", total, want)
+	}
+
+	// the snapshot resolves shards
+	snapshot := h3dist.Snapshot()
+	child := h3.ToChildren(city, Level5)[3]
+	have, ok := snapshot.Lookup(child)
+	if !ok {
+		t.Fatalf("have false, want true")
+	}
+	if want, _ := h3dist.Lookup(child); have != want {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+}
+
+func TestDistributed_MaxSplitDepth(t *testing.T) {
+	city := h3.FromGeo(h3.GeoCoord{Latitude: 51.5074, Longitude: -0.1278}, Level5)
+	h3dist, err := New(Level5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h3dist.MarkHot(city, Level5+MaxSplitDepth+1); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	if _, err := h3dist.MarkHotByLoad(map[h3.H3Index]float64{city: 1e12}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := h3dist.HotCells()[city], Level5+MaxSplitDepth; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+}
@@ -0,0 +1,101 @@
+package h3geodist
+
+import "github.com/uber/h3-go/v3"
+
+// WithSelf sets the host of the running instance,
+// so that the local ownership can be checked.
+func WithSelf(addr string) Option {
+	return func(d *Distributed) {
+		d.self = addr
+	}
+}
+
+// Ownership is a type to represent a change of the local ownership.
+type Ownership struct {
+	Epoch uint64
+	// Gained holds the vnodes moved to the local host.
+	Gained []uint64
+	// Lost holds the vnodes moved from the local host.
+	Lost []uint64
+	// Split holds the cells whose hot split level changed,
+	// their shards may be gained or lost without vnode moves.
+	Split []h3.H3Index
+}
+
+// Self returns the host of the running instance.
+func (d *Distributed) Self() string {
+	return d.self
+}
+
+// IsLocal returns TRUE if the cell is owned by the local host.
+func (d *Distributed) IsLocal(cell h3.H3Index) bool {
+	if d.self == "" {
+		return false
+	}
+	c, ok := d.Lookup(cell)
+	return ok && c.Host == d.self
+}
+
+// LocalVNodes returns the sorted vnodes owned by the local host.
+func (d *Distributed) LocalVNodes() []uint64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	vnodes := make([]uint64, 0, 8)
+	if d.self == "" {
+		return vnodes
+	}
+	for vnode := uint64(0); vnode < d.vnodes; vnode++ {
+		if n, ok := d.index[int(vnode)]; ok && n.addr == d.self {
+			vnodes = append(vnodes, vnode)
+		}
+	}
+	return vnodes
+}
+
+// OwnedCells iterate each distributed cell owned by the local host,
+// calling fn for each cell.
+func (d *Distributed) OwnedCells(iter func(c Cell)) {
+	if d.self == "" {
+		return
+	}
+	d.EachCell(func(c Cell) {
+		if c.Host == d.self {
+			iter(c)
+		}
+	})
+}
+
+// EachVNodeCell iterate each distributed cell of the vnodes,
+// calling fn for each cell. It is useful to load or evict
+// the data of gained or lost vnodes.
+func (d *Distributed) EachVNodeCell(vnodes []uint64, iter func(c Cell)) {
+	set := make(map[uint64]struct{}, len(vnodes))
+	for _, vnode := range vnodes {
+		set[vnode] = struct{}{}
+	}
+	d.EachCell(func(c Cell) {
+		if _, ok := set[uint64(d.VNodeIndex(c.H3ID))]; ok {
+			iter(c)
+		}
+	})
+}
+
+// OnOwnership calls fn when the local host gains or loses vnodes
+// on Add, Remove and other topology changes, see WatchHost.
+// Call stop to stop watching.
+func (d *Distributed) OnOwnership(fn func(o Ownership)) (stop func()) {
+	if d.self == "" {
+		return func() {}
+	}
+	return d.WatchHost(d.self, func(e Event) {
+		o := Ownership{Epoch: e.Epoch, Split: e.Split}
+		for _, m := range e.Moves {
+			if m.To == d.self {
+				o.Gained = append(o.Gained, m.VNode)
+			} else {
+				o.Lost = append(o.Lost, m.VNode)
+			}
+		}
+		fn(o)
+	})
+}
@@ -0,0 +1,96 @@
+package h3geodist
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_WithSelf(t *testing.T) {
+	h3dist, err := New(Level2, WithVNodes(64), WithSelf("host-a.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := h3dist.Self(), "host-a.com"; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+	changes := make(chan Ownership, 4)
+	stop := h3dist.OnOwnership(func(o Ownership) {
+		changes <- o
+	})
+	defer stop()
+	next := func() Ownership {
+		select {
+		case o := <-changes:
+			return o
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no ownership change")
+		}
+		return Ownership{}
+	}
+
+	_ = h3dist.Add("host-a.com")
+	if have, want := len(next().Gained), 64; have != want {
+		t.Fatalf("have %d, want %d gained vnodes", have, want)
+	}
+	_ = h3dist.Add("host-b.com")
+	o := next()
+	if len(o.Lost) == 0 || len(o.Gained) != 0 {
+		t.Fatalf("unexpected ownership change %+v", o)
+	}
+
+	local := h3dist.LocalVNodes()
+	if have, want := len(local), 64-len(o.Lost); have != want {
+		t.Fatalf("have %d, want %d local vnodes", have, want)
+	}
+	var owned int
+	h3dist.OwnedCells(func(c Cell) {
+		owned++
+		if !h3dist.IsLocal(c.H3ID) {
+			t.Fatalf("h3dist.IsLocal(%v) => false, expected true", c)
+		}
+	})
+	var lost int
+	h3dist.EachVNodeCell(o.Lost, func(c Cell) {
+		lost++
+		if h3dist.IsLocal(c.H3ID) {
+			t.Fatalf("h3dist.IsLocal(%v) => true, expected false", c)
+		}
+	})
+	if have, want := uint(owned+lost), NumCells(Level2); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+
+	h3dist.Remove("host-b.com")
+	if have, want := len(next().Gained), len(o.Lost); have != want {
+		t.Fatalf("have %d, want %d gained vnodes", have, want)
+	}
+}
+
+func TestDistributed_OnOwnershipSplit(t *testing.T) {
+	h3dist, err := New(Level2, WithSelf("host-a.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = h3dist.Add("host-a.com")
+	_ = h3dist.Add("host-b.com")
+	changes := make(chan Ownership, 4)
+	stop := h3dist.OnOwnership(func(o Ownership) {
+		changes <- o
+	})
+	defer stop()
+	cell := h3.FromGeo(h3.GeoCoord{Latitude: 52.52, Longitude: 13.405}, Level2)
+	if err := h3dist.MarkHot(cell, Level3); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case o := <-changes:
+		if have, want := o.Split, []h3.H3Index{cell}; !reflect.DeepEqual(have, want) {
+			t.Fatalf("have %v, want %v", have, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no ownership change")
+	}
+}
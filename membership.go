@@ -0,0 +1,251 @@
+package h3geodist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDebounce is the delay before a membership update is applied.
+const DefaultDebounce = time.Second
+
+// MembershipSource is a source of the list of nodes.
+type MembershipSource interface {
+	// Watch calls fn with the current list of nodes and on every change
+	// until the context is done or the source fails.
+	Watch(ctx context.Context, fn func(members []string)) error
+}
+
+// Follow keeps the nodes in sync with the source until the context is done.
+// Updates are debounced: the latest list is applied with SetNodes
+// as one batch after no updates came within the debounce delay.
+// A delay less than or equal to 0 applies updates at once.
+func (d *Distributed) Follow(ctx context.Context, src MembershipSource, debounce time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates := make(chan []string, 1)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- src.Watch(ctx, func(members []string) {
+			// keep only the latest list
+			select {
+			case <-updates:
+			default:
+			}
+			updates <- members
+		})
+	}()
+	var (
+		pending []string
+		timer   *time.Timer
+		fire    <-chan time.Time
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errc:
+			if err == nil || ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		case members := <-updates:
+			pending = members
+			if debounce <= 0 {
+				if err := d.SetNodes(pending); err != nil {
+					return err
+				}
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(debounce)
+			fire = timer.C
+		case <-fire:
+			fire = nil
+			if err := d.SetNodes(pending); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type staticSource []string
+
+// NewStaticSource returns the source with the fixed list of nodes.
+func NewStaticSource(addrs ...string) MembershipSource {
+	return staticSource(append([]string(nil), addrs...))
+}
+
+func (s staticSource) Watch(ctx context.Context, fn func(members []string)) error {
+	fn(append([]string(nil), s...))
+	<-ctx.Done()
+	return nil
+}
+
+type fileSource struct {
+	filename string
+	interval time.Duration
+}
+
+// NewFileSource returns the source reading the nodes from the file,
+// which is checked for changes every interval.
+// The file is a JSON (.json) or YAML (.yaml, .yml) list of nodes
+// or an object with the list in the "nodes" field.
+func NewFileSource(filename string, interval time.Duration) MembershipSource {
+	return fileSource{filename: filename, interval: interval}
+}
+
+func (s fileSource) Watch(ctx context.Context, fn func(members []string)) error {
+	var last []byte
+	return poll(ctx, s.interval, func() error {
+		data, err := os.ReadFile(s.filename)
+		if err != nil {
+			return err
+		}
+		if last != nil && bytes.Equal(data, last) {
+			return nil
+		}
+		members, err := parseMembers(s.filename, data)
+		if err != nil {
+			return err
+		}
+		last = data
+		fn(members)
+		return nil
+	})
+}
+
+// parseMembers parses the list of nodes by the file extension.
+func parseMembers(filename string, data []byte) ([]string, error) {
+	unmarshal := json.Unmarshal
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".json":
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	default:
+		return nil, fmt.Errorf("h3geodist: unsupported membership file %s", ext)
+	}
+	var members []string
+	if err := unmarshal(data, &members); err == nil {
+		return members, nil
+	}
+	var doc struct {
+		Nodes []string `json:"nodes" yaml:"nodes"`
+	}
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("h3geodist: membership file %s: %w", filename, err)
+	}
+	return doc.Nodes, nil
+}
+
+type dnsSource struct {
+	name     string
+	port     int
+	srv      bool
+	interval time.Duration
+	resolver *net.Resolver
+}
+
+// NewDNSSRVSource returns the source polling the SRV records of the name
+// (for example _grpc._tcp.example.com) every interval.
+// Nodes are the targets with the ports of the records.
+// A nil resolver means net.DefaultResolver.
+func NewDNSSRVSource(name string, interval time.Duration, resolver *net.Resolver) MembershipSource {
+	return dnsSource{name: name, srv: true, interval: interval, resolver: resolver}
+}
+
+// NewDNSASource returns the source polling the A and AAAA records
+// of the host every interval. Nodes are the addresses with the port.
+// A nil resolver means net.DefaultResolver.
+func NewDNSASource(host string, port int, interval time.Duration, resolver *net.Resolver) MembershipSource {
+	return dnsSource{name: host, port: port, interval: interval, resolver: resolver}
+}
+
+func (s dnsSource) Watch(ctx context.Context, fn func(members []string)) error {
+	resolver := s.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	var last []string
+	return poll(ctx, s.interval, func() error {
+		members, err := s.lookup(ctx, resolver)
+		if err != nil {
+			return err
+		}
+		if last != nil && equalStrings(members, last) {
+			return nil
+		}
+		last = members
+		fn(members)
+		return nil
+	})
+}
+
+func (s dnsSource) lookup(ctx context.Context, resolver *net.Resolver) ([]string, error) {
+	members := make([]string, 0, 4)
+	if s.srv {
+		_, records, err := resolver.LookupSRV(ctx, "", "", s.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			target := strings.TrimSuffix(r.Target, ".")
+			members = append(members, net.JoinHostPort(target, strconv.Itoa(int(r.Port))))
+		}
+	} else {
+		addrs, err := resolver.LookupIPAddr(ctx, s.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			members = append(members, net.JoinHostPort(addr.IP.String(), strconv.Itoa(s.port)))
+		}
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+// poll calls fn at once and every interval until the context is done.
+// Only the error of the first call is returned, later errors are
+// treated as transient and the last list of nodes is kept.
+func poll(ctx context.Context, interval time.Duration, fn func() error) error {
+	if interval <= 0 {
+		interval = DefaultDebounce
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for first := true; ; first = false {
+		if err := fn(); err != nil && first {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package h3geodist
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func waitNodes(t *testing.T, h3dist *Distributed, want []string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		have := h3dist.Nodes()
+		sort.Strings(have)
+		if reflect.DeepEqual(have, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("have %v, want %v", have, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDistributed_FollowStatic(t *testing.T) {
+	h3dist, err := New(Level2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = h3dist.Add("host-z.com")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- h3dist.Follow(ctx, NewStaticSource("host-a.com", "host-b.com"), 10*time.Millisecond)
+	}()
+	waitNodes(t, h3dist, []string{"host-a.com", "host-b.com"})
+	if have, want := h3dist.Epoch(), uint64(2); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("have %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestDistributed_FollowFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "nodes.yaml")
+	if err := os.WriteFile(filename, []byte("- host-a.com\n- host-b.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h3dist, err := New(Level2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = h3dist.Follow(ctx, NewFileSource(filename, 10*time.Millisecond), 0)
+	}()
+	waitNodes(t, h3dist, []string{"host-a.com", "host-b.com"})
+	if err := os.WriteFile(filename, []byte("nodes:\n  - host-b.com\n  - host-c.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitNodes(t, h3dist, []string{"host-b.com", "host-c.com"})
+
+	members, err := parseMembers("nodes.json", []byte(`["host-d.com"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := members, []string{"host-d.com"}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+	if _, err := parseMembers("nodes.txt", nil); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	err = h3dist.Follow(ctx, NewFileSource(filepath.Join(dir, "missing.json"), time.Second), 0)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("have %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+// dnsStub is a local DNS server answering A and SRV queries.
+type dnsStub struct {
+	mu   sync.Mutex
+	a    []net.IP
+	srv  []dnsmessage.SRVResource
+	conn net.PacketConn
+}
+
+func newDNSStub(t *testing.T) *dnsStub {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &dnsStub{conn: conn}
+	t.Cleanup(func() { _ = conn.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *dnsStub) resolver() *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", s.conn.LocalAddr().String())
+		},
+	}
+}
+
+func (s *dnsStub) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var req dnsmessage.Message
+		if err := req.Unpack(buf[:n]); err != nil || len(req.Questions) == 0 {
+			continue
+		}
+		q := req.Questions[0]
+		resp := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: req.ID, Response: true, Authoritative: true},
+			Questions: req.Questions,
+		}
+		hdr := dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 1}
+		s.mu.Lock()
+		switch q.Type {
+		case dnsmessage.TypeA:
+			for _, ip := range s.a {
+				var a dnsmessage.AResource
+				copy(a.A[:], ip.To4())
+				resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &a})
+			}
+		case dnsmessage.TypeSRV:
+			for i := range s.srv {
+				resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &s.srv[i]})
+			}
+		}
+		s.mu.Unlock()
+		packed, err := resp.Pack()
+		if err != nil {
+			continue
+		}
+		_, _ = s.conn.WriteTo(packed, addr)
+	}
+}
+
+func TestDistributed_FollowDNS(t *testing.T) {
+	stub := newDNSStub(t)
+	stub.mu.Lock()
+	stub.a = []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1")}
+	stub.srv = []dnsmessage.SRVResource{
+		{Port: 8080, Target: dnsmessage.MustNewName("node-1.example.com.")},
+		{Port: 8081, Target: dnsmessage.MustNewName("node-2.example.com.")},
+	}
+	stub.mu.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	byA, _ := New(Level2)
+	go func() {
+		_ = byA.Follow(ctx, NewDNSASource("nodes.example.com", 9000, 10*time.Millisecond, stub.resolver()), 0)
+	}()
+	waitNodes(t, byA, []string{"10.0.0.1:9000", "10.0.0.2:9000"})
+
+	bySRV, _ := New(Level2)
+	go func() {
+		_ = bySRV.Follow(ctx, NewDNSSRVSource("_h3._tcp.example.com", 10*time.Millisecond, stub.resolver()), 0)
+	}()
+	waitNodes(t, bySRV, []string{"node-1.example.com:8080", "node-2.example.com:8081"})
+
+	stub.mu.Lock()
+	stub.a = stub.a[:1]
+	stub.mu.Unlock()
+	waitNodes(t, byA, []string{"10.0.0.2:9000"})
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/mmadfox/go-h3geo-dist"
+	"github.com/mmadfox/go-h3geo-dist/proxy"
+	"github.com/uber/h3-go/v3"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain and ErrorReason identify the wrong-owner gRPC status details.
+const (
+	ErrorDomain = "h3geodist"
+	ErrorReason = "WRONG_OWNER"
+)
+
+// GRPCExtractor extracts the routing key from the incoming call.
+// The request is nil for streams.
+// It returns proxy.ErrNoKey if the call carries no key.
+type GRPCExtractor func(ctx context.Context, req interface{}) (proxy.Key, error)
+
+// MetadataCell extracts the hex cell from the incoming metadata.
+func MetadataCell(name string) GRPCExtractor {
+	return func(ctx context.Context, _ interface{}) (proxy.Key, error) {
+		return proxy.ParseCell(metadataValue(ctx, name))
+	}
+}
+
+// MetadataLatLon extracts the coordinate from the incoming metadata.
+func MetadataLatLon(latName, lonName string) GRPCExtractor {
+	return func(ctx context.Context, _ interface{}) (proxy.Key, error) {
+		return proxy.ParseLatLon(metadataValue(ctx, latName), metadataValue(ctx, lonName))
+	}
+}
+
+func metadataValue(ctx context.Context, name string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// UnaryServerInterceptor returns the interceptor passing calls for cells
+// owned by the local host. Calls for other cells fail with FailedPrecondition
+// naming the owner and the epoch, see WrongOwner.
+// It panics if the local host is not set with h3geodist.WithSelf.
+func UnaryServerInterceptor(d *h3geodist.Distributed, key GRPCExtractor) grpc.UnaryServerInterceptor {
+	mustSelf(d)
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, d, key, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the interceptor passing streams for cells
+// owned by the local host, see UnaryServerInterceptor.
+// The key is extracted from the incoming metadata.
+func StreamServerInterceptor(d *h3geodist.Distributed, key GRPCExtractor) grpc.StreamServerInterceptor {
+	mustSelf(d)
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), d, key, nil); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, d *h3geodist.Distributed, key GRPCExtractor, req interface{}) error {
+	k, err := key(ctx, req)
+	if errors.Is(err, proxy.ErrNoKey) {
+		return nil
+	}
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	o, err := check(d, k)
+	switch {
+	case errors.Is(err, proxy.ErrCellLevel):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, h3geodist.ErrOutOfCoverage):
+		return status.Error(codes.NotFound, err.Error())
+	case err != nil:
+		return status.Error(codes.Unavailable, err.Error())
+	case o.local:
+		return nil
+	}
+	st := status.New(codes.FailedPrecondition,
+		fmt.Sprintf("middleware: cell is owned by %s", o.cell.Host))
+	st, err = st.WithDetails(&errdetails.ErrorInfo{
+		Reason: ErrorReason,
+		Domain: ErrorDomain,
+		Metadata: map[string]string{
+			"owner": o.cell.Host,
+			"epoch": o.epochString(),
+			"cell":  h3.ToString(o.cell.H3ID),
+		},
+	})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return st.Err()
+}
+
+// WrongOwner returns the owner and the epoch
+// of the wrong-owner status returned by the interceptors.
+func WrongOwner(err error) (owner string, epoch uint64, ok bool) {
+	st, isStatus := status.FromError(err)
+	if !isStatus || st.Code() != codes.FailedPrecondition {
+		return "", 0, false
+	}
+	for _, detail := range st.Details() {
+		info, isInfo := detail.(*errdetails.ErrorInfo)
+		if !isInfo || info.Domain != ErrorDomain || info.Reason != ErrorReason {
+			continue
+		}
+		epoch, err := strconv.ParseUint(info.Metadata["epoch"], 10, 64)
+		if err != nil {
+			return "", 0, false
+		}
+		return info.Metadata["owner"], epoch, true
+	}
+	return "", 0, false
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPC(t *testing.T) {
+	h3dist, local, remote := newDist(t)
+	key := MetadataCell("h3-cell")
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(h3dist, key)),
+		grpc.StreamInterceptor(StreamServerInterceptor(h3dist, key)),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	call := func(cell string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "h3-cell", cell)
+	}
+
+	if _, err := client.Check(call(h3.ToString(local)), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Check(call(h3.ToString(remote)), &healthpb.HealthCheckRequest{})
+	owner, epoch, ok := WrongOwner(err)
+	if !ok {
+		t.Fatalf("have %v, want wrong owner", err)
+	}
+	if have, want := owner, remoteHost; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+	if have, want := epoch, h3dist.Epoch(); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	_, err = client.Check(call("zzz"), &healthpb.HealthCheckRequest{})
+	if have, want := status.Code(err), codes.InvalidArgument; have != want {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+
+	stream, err := client.Watch(call(h3.ToString(remote)), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	if owner, _, ok := WrongOwner(err); !ok || owner != remoteHost {
+		t.Fatalf("have %v, want wrong owner %s", err, remoteHost)
+	}
+	stream, err = client.Watch(call(h3.ToString(local)), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+}
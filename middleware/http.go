@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mmadfox/go-h3geo-dist"
+	"github.com/mmadfox/go-h3geo-dist/proxy"
+	"github.com/uber/h3-go/v3"
+)
+
+// HTTP returns the middleware passing requests for cells owned
+// by the local host. Requests for other cells get 307 Temporary Redirect
+// to the owner with the owner and the epoch in headers.
+// Keys outside the coverage get 404 Not Found,
+// invalid keys and cells coarser than the level get 400 Bad Request.
+// It panics if the local host is not set with h3geodist.WithSelf.
+func HTTP(d *h3geodist.Distributed, key proxy.Extractor, opts ...Option) func(next http.Handler) http.Handler {
+	mustSelf(d)
+	conf := newConfig(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k, err := key(r)
+			if errors.Is(err, proxy.ErrNoKey) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			o, err := check(d, k)
+			switch {
+			case errors.Is(err, proxy.ErrCellLevel):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			case errors.Is(err, h3geodist.ErrOutOfCoverage):
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			case o.local:
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set(OwnerHeader, o.cell.Host)
+			w.Header().Set(EpochHeader, o.epochString())
+			w.Header().Set(proxy.CellHeader, h3.ToString(o.cell.H3ID))
+			if conf.reject {
+				http.Error(w, "middleware: cell is owned by "+o.cell.Host, http.StatusMisdirectedRequest)
+				return
+			}
+			location := conf.scheme + "://" + o.cell.Host + r.URL.RequestURI()
+			http.Redirect(w, r, location, http.StatusTemporaryRedirect)
+		})
+	}
+}
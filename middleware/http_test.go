@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mmadfox/go-h3geo-dist"
+	"github.com/mmadfox/go-h3geo-dist/proxy"
+	"github.com/uber/h3-go/v3"
+)
+
+const (
+	localHost  = "host-a.com:8080"
+	remoteHost = "host-b.com:8080"
+)
+
+// newDist returns the distribution with a cell of each host.
+func newDist(t *testing.T) (h3dist *h3geodist.Distributed, local, remote h3.H3Index) {
+	h3dist, err := h3geodist.New(h3geodist.Level2, h3geodist.WithSelf(localHost))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{localHost, remoteHost} {
+		if err := h3dist.Add(addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h3dist.EachCell(func(c h3geodist.Cell) {
+		switch {
+		case c.Host == localHost && local == 0:
+			local = c.H3ID
+		case c.Host == remoteHost && remote == 0:
+			remote = c.H3ID
+		}
+	})
+	return h3dist, local, remote
+}
+
+func TestHTTP(t *testing.T) {
+	h3dist, local, remote := newDist(t)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := HTTP(h3dist, proxy.QueryCell("cell"))(next)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/objects?cell="+h3.ToString(local), nil))
+	if have, want := w.Code, http.StatusNoContent; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/objects?cell="+h3.ToString(remote), nil))
+	if have, want := w.Code, http.StatusTemporaryRedirect; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if have, want := w.Header().Get("Location"), "http://"+remoteHost+"/objects?cell="+h3.ToString(remote); have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+	if have, want := w.Header().Get(OwnerHeader), remoteHost; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+	if have, want := w.Header().Get(EpochHeader), "2"; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/objects", nil))
+	if have, want := w.Code, http.StatusNoContent; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/objects?cell=zzz", nil))
+	if have, want := w.Code, http.StatusBadRequest; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/objects?cell="+h3.ToString(h3.ToParent(local, h3geodist.Level1)), nil))
+	if have, want := w.Code, http.StatusBadRequest; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/objects?cell="+h3.ToString(h3.ToCenterChild(local, 9)), nil))
+	if have, want := w.Code, http.StatusNoContent; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	handler = HTTP(h3dist, proxy.QueryCell("cell"), WithReject())(next)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/objects?cell="+h3.ToString(remote), nil))
+	if have, want := w.Code, http.StatusMisdirectedRequest; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if have, want := w.Header().Get(OwnerHeader), remoteHost; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+}
+
+func TestWithoutSelf(t *testing.T) {
+	h3dist, err := h3geodist.New(h3geodist.Level2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	constructors := map[string]func(){
+		"http":   func() { HTTP(h3dist, proxy.QueryCell("cell")) },
+		"unary":  func() { UnaryServerInterceptor(h3dist, MetadataCell("cell")) },
+		"stream": func() { StreamServerInterceptor(h3dist, MetadataCell("cell")) },
+	}
+	for name, fn := range constructors {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: have no panic, want panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
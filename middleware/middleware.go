@@ -0,0 +1,92 @@
+// Package middleware provides net/http middleware and gRPC interceptors
+// rejecting requests for cells the local host does not own,
+// for example from clients holding a stale topology:
+//
+//	h3dist, _ := h3geodist.New(h3geodist.Level5, h3geodist.WithSelf("10.0.0.1:8080"))
+//	handler = middleware.HTTP(h3dist, proxy.QueryCell("cell"))(handler)
+//
+// Requests without a key are passed through.
+// The local host must be set with h3geodist.WithSelf,
+// the middleware and interceptors panic otherwise.
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/mmadfox/go-h3geo-dist"
+	"github.com/mmadfox/go-h3geo-dist/proxy"
+)
+
+const (
+	// OwnerHeader is the header naming the owner of the requested cell.
+	OwnerHeader = "X-H3-Owner"
+	// EpochHeader is the header with the topology epoch of the owner.
+	EpochHeader = "X-H3-Epoch"
+)
+
+// Option is a type to represent the middleware configuration.
+type Option func(*config)
+
+type config struct {
+	scheme string
+	reject bool
+}
+
+// WithScheme sets the scheme of redirects, http by default.
+func WithScheme(scheme string) Option {
+	return func(c *config) {
+		c.scheme = scheme
+	}
+}
+
+// WithReject rejects wrong-owner HTTP requests
+// with 421 Misdirected Request instead of the redirect.
+func WithReject() Option {
+	return func(c *config) {
+		c.reject = true
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{scheme: "http"}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// owner is a type to represent the result of the ownership check.
+type owner struct {
+	cell  h3geodist.Cell
+	epoch uint64
+	local bool
+}
+
+// check resolves the owner of the key with the epoch it belongs to.
+// The epoch is raised with every change of owners, so the lookup
+// is repeated until no change happened in between.
+func check(d *h3geodist.Distributed, key proxy.Key) (owner, error) {
+	for {
+		epoch := d.Epoch()
+		c, err := proxy.Lookup(d, key)
+		if d.Epoch() != epoch {
+			continue
+		}
+		if err != nil {
+			return owner{}, err
+		}
+		return owner{cell: c, epoch: epoch, local: c.Host == d.Self()}, nil
+	}
+}
+
+// mustSelf panics if the local host of the distribution is not set,
+// every keyed request would be redirected otherwise.
+func mustSelf(d *h3geodist.Distributed) {
+	if d.Self() == "" {
+		panic("middleware: local host is not set, see h3geodist.WithSelf")
+	}
+}
+
+func (o owner) epochString() string {
+	return strconv.FormatUint(o.epoch, 10)
+}
@@ -0,0 +1,122 @@
+package h3geodist
+
+import (
+	"math"
+	"sort"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// partition is a type to represent the distributed cells:
+// the cells of the level within the coverage,
+// with hot cells replaced by their shards.
+type partition struct {
+	level int
+	// hot holds the split levels of hot cells.
+	hot map[h3.H3Index]int
+	// coverage holds the sorted covered cells of the level,
+	// nil means the whole globe.
+	coverage []h3.H3Index
+}
+
+func (d *Distributed) partition() partition {
+	return partition{level: d.level, hot: d.hot, coverage: d.coverage}
+}
+
+// covers returns TRUE if the cell is within the coverage.
+// A cell coarser than the level is never covered with a coverage set.
+func (p partition) covers(cell h3.H3Index) bool {
+	if p.coverage == nil {
+		return true
+	}
+	res := h3.Resolution(cell)
+	if res < p.level {
+		return false
+	}
+	if res > p.level {
+		cell = h3.ToParent(cell, p.level)
+	}
+	i := sort.Search(len(p.coverage), func(i int) bool {
+		return p.coverage[i] >= cell
+	})
+	return i < len(p.coverage) && p.coverage[i] == cell
+}
+
+// shard returns the distributed cell for the cell.
+// For a hot cell it is the child at the split level,
+// a hot cell itself resolves to its center child.
+func (p partition) shard(cell h3.H3Index) h3.H3Index {
+	if len(p.hot) == 0 {
+		return cell
+	}
+	res := h3.Resolution(cell)
+	if res < p.level {
+		return cell
+	}
+	parent := cell
+	if res > p.level {
+		parent = h3.ToParent(cell, p.level)
+	}
+	split, ok := p.hot[parent]
+	switch {
+	case !ok:
+		return cell
+	case res >= split:
+		return h3.ToParent(cell, split)
+	default:
+		return h3.ToCenterChild(cell, split)
+	}
+}
+
+// shardAt returns the distributed cell for the geographic coordinate.
+// Children don't nest exactly into the parent, so near the edge
+// of a hot cell the closest child of the cell is chosen.
+func (p partition) shardAt(ll LatLon) h3.H3Index {
+	cell := h3.FromGeo(ll.toGeo(), p.level)
+	split, ok := p.hot[cell]
+	if !ok {
+		return cell
+	}
+	child := h3.FromGeo(ll.toGeo(), split)
+	if h3.ToParent(child, p.level) == cell {
+		return child
+	}
+	nearest, minDist := h3.ToCenterChild(cell, split), math.Inf(1)
+	for _, next := range h3.KRing(child, 1) {
+		if h3.ToParent(next, p.level) != cell {
+			continue
+		}
+		if dist := distanceM(ll, latLonFromGeo(h3.ToGeo(next))); dist < minDist {
+			nearest, minDist = next, dist
+		}
+	}
+	return nearest
+}
+
+// eachCell iterate each covered cell of the level, calling fn for each cell.
+func (p partition) eachCell(fn func(cell h3.H3Index)) {
+	if p.coverage == nil {
+		Iter(p.level, func(_ uint, cell h3.H3Index) {
+			fn(cell)
+		})
+		return
+	}
+	for _, cell := range p.coverage {
+		fn(cell)
+	}
+}
+
+// eachShard iterate each covered cell of the level, calling fn for each cell
+// or for each child of a hot cell.
+func (p partition) eachShard(fn func(cell h3.H3Index)) {
+	p.eachCell(func(cell h3.H3Index) {
+		split, ok := p.hot[cell]
+		if !ok {
+			fn(cell)
+			return
+		}
+		for _, child := range h3.ToChildren(cell, split) {
+			fn(child)
+		}
+	})
+}
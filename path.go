@@ -0,0 +1,117 @@
+package h3geodist
+
+import (
+	"math"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// boundaryPrecisionM is the precision (meters) of host boundary crossings.
+const boundaryPrecisionM = 1.0
+
+// PathSegment is a type to represent a part of a path
+// that passes through the cells of a single host.
+type PathSegment struct {
+	Host   string
+	Cells  []h3.H3Index
+	Entry  LatLon
+	Exit   LatLon
+	StartM float64
+	EndM   float64
+}
+
+// LengthM returns the length of the segment in meters.
+func (s PathSegment) LengthM() float64 {
+	return s.EndM - s.StartM
+}
+
+// LookupPath traces a polyline across the distributed cells
+// and returns the ordered list of host segments.
+// Points are connected by great circle arcs, so the cells between
+// sparse points are taken into account.
+// StartM and EndM of each segment are measured along the path from its first point.
+// Host is empty for the parts of the path outside the coverage.
+func (d *Distributed) LookupPath(path []LatLon) ([]PathSegment, error) {
+	if len(path) == 0 {
+		return nil, ErrEmptyPath
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	cell, addr, ok := d.locate(path[0])
+	if !ok {
+		return nil, ErrVNodes
+	}
+	step := d.sampleStepM()
+	segments := make([]PathSegment, 0, 4)
+	segment := PathSegment{Host: addr, Cells: []h3.H3Index{cell}, Entry: path[0]}
+	lastCell := cell
+	var offset float64
+	for i := 1; i < len(path); i++ {
+		from, to := path[i-1], path[i]
+		length := distanceM(from, to)
+		steps := int(math.Ceil(length / step))
+		for j := 1; j <= steps; j++ {
+			f := float64(j) / float64(steps)
+			cell, addr, ok := d.locate(intermediate(from, to, f))
+			if !ok {
+				return nil, ErrVNodes
+			}
+			if cell == lastCell {
+				continue
+			}
+			lastCell = cell
+			if addr != segment.Host {
+				lo, hi := d.crossing(from, to, length, float64(j-1)/float64(steps), f, segment.Host)
+				bf := (lo + hi) / 2
+				point := intermediate(from, to, bf)
+				segment.Exit = point
+				segment.EndM = offset + length*bf
+				segments = append(segments, segment)
+				segment = PathSegment{Host: addr, Entry: point, StartM: segment.EndM}
+			}
+			segment.Cells = append(segment.Cells, cell)
+		}
+		offset += length
+	}
+	segment.Exit = path[len(path)-1]
+	segment.EndM = offset
+	return append(segments, segment), nil
+}
+
+// crossing narrows the fractions lo and hi of the arc from a to b
+// down to the point where the path leaves the territory of the host.
+func (d *Distributed) crossing(a, b LatLon, length, lo, hi float64, host string) (float64, float64) {
+	for length*(hi-lo) > boundaryPrecisionM {
+		mid := (lo + hi) / 2
+		_, addr, _ := d.locate(intermediate(a, b, mid))
+		if addr == host {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, hi
+}
+
+// sampleStepM returns the distance (meters) between samples along an arc,
+// a quarter of the edge of the finest distributed cells,
+// so the shards of hot cells do not fall between samples.
+func (d *Distributed) sampleStepM() float64 {
+	level := d.level
+	if len(d.hot) > 0 {
+		level = d.maxSplitLevel()
+	}
+	return h3.EdgeLengthM(level) / 4
+}
+
+// locate returns the distributed cell and its host for a geographic coordinate.
+// The host of a cell outside the coverage is empty.
+func (d *Distributed) locate(ll LatLon) (cell h3.H3Index, addr string, ok bool) {
+	p := d.partition()
+	cell = p.shardAt(ll)
+	if !p.covers(cell) {
+		return cell, "", len(d.nodes) > 0
+	}
+	addr, ok = d.lookup(cell)
+	return
+}
@@ -0,0 +1,149 @@
+package h3geodist
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_LookupPath(t *testing.T) {
+	h3dist, err := New(Level6, WithVNodes(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = h3dist.Add("127.0.0.1")
+	_ = h3dist.Add("127.0.0.2")
+	_ = h3dist.Add("127.0.0.3")
+
+	path := []LatLon{
+		{Lat: 42.9325219, Lon: -72.2822266},
+		{Lat: 42.8678295, Lon: -72.2744981},
+		{Lat: 42.6512010, Lon: -72.0151210},
+	}
+	segments, err := h3dist.LookupPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) < 2 {
+		t.Fatalf("have %d, want > 1 segments", len(segments))
+	}
+
+	first, last := segments[0], segments[len(segments)-1]
+	if first.Entry != path[0] {
+		t.Fatalf("have %v, want %v entry", first.Entry, path[0])
+	}
+	if last.Exit != path[len(path)-1] {
+		t.Fatalf("have %v, want %v exit", last.Exit, path[len(path)-1])
+	}
+	want := distanceM(path[0], path[1]) + distanceM(path[1], path[2])
+	if have := last.EndM; math.Abs(have-want) > 1e-6 {
+		t.Fatalf("have %f, want %f path length", have, want)
+	}
+
+	for i, segment := range segments {
+		if len(segment.Cells) == 0 {
+			t.Fatalf("segment %d has no cells", i)
+		}
+		for _, cell := range segment.Cells {
+			dcell, _ := h3dist.Lookup(cell)
+			if have, want := dcell.Host, segment.Host; have != want {
+				t.Fatalf("have %s, want %s", have, want)
+			}
+		}
+		if segment.LengthM() < 0 {
+			t.Fatalf("have %f, want >= 0 segment length", segment.LengthM())
+		}
+		if i == 0 {
+			continue
+		}
+		prev := segments[i-1]
+		if prev.Host == segment.Host {
+			t.Fatalf("segments %d and %d have the same host %s", i-1, i, segment.Host)
+		}
+		if prev.Exit != segment.Entry {
+			t.Fatalf("have %v, want %v entry", segment.Entry, prev.Exit)
+		}
+		if prev.EndM != segment.StartM {
+			t.Fatalf("have %f, want %f start", segment.StartM, prev.EndM)
+		}
+		exitCell := h3.FromGeo(prev.Exit.toGeo(), Level6)
+		lastCell := prev.Cells[len(prev.Cells)-1]
+		firstCell := segment.Cells[0]
+		if exitCell != lastCell && exitCell != firstCell {
+			t.Fatalf("exit point %v is not on the boundary of %s and %s",
+				prev.Exit, h3.ToString(lastCell), h3.ToString(firstCell))
+		}
+	}
+}
+
+func TestDistributed_LookupPathWithRegion(t *testing.T) {
+	region := Polygon{Outer: []LatLon{
+		{Lat: 50.5, Lon: 10.5}, {Lat: 50.5, Lon: 15.5},
+		{Lat: 53.5, Lon: 15.5}, {Lat: 53.5, Lon: 10.5},
+	}}
+	h3dist, err := New(Level3, WithRegion(Level6, region))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 6; i++ {
+		_ = h3dist.Add(fmt.Sprintf("127.0.0.%d", i))
+	}
+	from, to := LatLon{Lat: 51, Lon: 11}, LatLon{Lat: 53, Lon: 15}
+	segments, err := h3dist.LookupPath([]LatLon{from, to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the shards of the region are sampled densely
+	length := distanceM(from, to)
+	steps := int(length / (h3.EdgeLengthM(Level6) / 8))
+	var changes int
+	prev, _ := h3dist.LookupFromLatLon(from.Lat, from.Lon)
+	for j := 1; j <= steps; j++ {
+		ll := intermediate(from, to, float64(j)/float64(steps))
+		c, err := h3dist.LookupFromLatLon(ll.Lat, ll.Lon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Host != prev.Host {
+			changes++
+		}
+		prev = c
+	}
+	// corners of shards narrower than the sampling step may be grazed
+	if have, want := len(segments)-1, changes-changes/20; have < want {
+		t.Fatalf("have %d, want >= %d host changes", have, want)
+	}
+}
+
+func TestDistributed_LookupPathSinglePoint(t *testing.T) {
+	h3dist, _ := New(Level3)
+	_ = h3dist.Add("127.0.0.1")
+	point := LatLon{Lat: 42.9269778, Lon: -72.2796935}
+	segments, err := h3dist.LookupPath([]LatLon{point, point})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(segments), 1; have != want {
+		t.Fatalf("have %d, want %d segments", have, want)
+	}
+	if have, want := len(segments[0].Cells), 1; have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+	if have, want := segments[0].LengthM(), 0.0; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+}
+
+func TestDistributed_LookupPathWithError(t *testing.T) {
+	h3dist := Default()
+	if _, err := h3dist.LookupPath(nil); !errors.Is(err, ErrEmptyPath) {
+		t.Fatalf("have %v, want %v error", err, ErrEmptyPath)
+	}
+	_, err := h3dist.LookupPath([]LatLon{{Lat: 42.9269778, Lon: -72.2796935}})
+	if !errors.Is(err, ErrVNodes) {
+		t.Fatalf("have %v, want %v error", err, ErrVNodes)
+	}
+}
@@ -0,0 +1,142 @@
+package h3geodist
+
+import (
+	"sync"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// Pool is a pool of clients of hosts kept in sync with the topology:
+// clients are created for added hosts and closed for removed ones.
+// A removed host that still owns vnodes while draining keeps its client.
+// Thread-safe.
+type Pool[T any] struct {
+	dist    *Distributed
+	dial    func(addr string) (T, error)
+	close   func(client T) error
+	mu      sync.Mutex
+	clients map[string]T
+	closed  bool
+	stop    func()
+}
+
+// NewPool creates and returns a new pool of clients of the distribution nodes.
+// Dial creates the client of the host, close closes it.
+// Hosts that failed to dial are dialed again on the first use.
+func NewPool[T any](d *Distributed, dial func(addr string) (T, error), close func(client T) error) *Pool[T] {
+	p := &Pool[T]{
+		dist:    d,
+		dial:    dial,
+		close:   close,
+		clients: make(map[string]T),
+	}
+	p.sync()
+	p.stop = d.Watch(func(e Event) {
+		p.sync()
+	})
+	return p
+}
+
+// For returns the client of the host owning the cell.
+// A cell finer than the level resolves to its parent distributed cell,
+// a cell coarser than the level is an error, see WhereIsMyParent.
+func (p *Pool[T]) For(cell h3.H3Index) (T, error) {
+	var zero T
+	if h3.Resolution(cell) != p.dist.Level() {
+		c, err := p.dist.WhereIsMyParent(cell)
+		if err == nil {
+			return p.Get(c.Host)
+		}
+		if !p.dist.withinHot(cell) {
+			return zero, err
+		}
+		// a cell coarser than the split level of a hot cell
+		// resolves to its shard by Lookup
+	}
+	c, ok := p.dist.Lookup(cell)
+	if !ok {
+		if !p.dist.Covers(cell) {
+			return zero, ErrOutOfCoverage
+		}
+		return zero, ErrVNodes
+	}
+	return p.Get(c.Host)
+}
+
+// Get returns the client of the host, dialing it if necessary.
+func (p *Pool[T]) Get(addr string) (T, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		var zero T
+		return zero, ErrPoolClosed
+	}
+	if client, ok := p.clients[addr]; ok {
+		return client, nil
+	}
+	client, err := p.dial(addr)
+	if err != nil {
+		return client, err
+	}
+	p.clients[addr] = client
+	return client, nil
+}
+
+// Hosts returns the number of hosts with clients.
+func (p *Pool[T]) Hosts() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// Close stops following the topology and closes all clients.
+// It returns the first close error.
+func (p *Pool[T]) Close() error {
+	p.stop()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	var first error
+	for addr, client := range p.clients {
+		if err := p.close(client); err != nil && first == nil {
+			first = err
+		}
+		delete(p.clients, addr)
+	}
+	return first
+}
+
+// sync dials the nodes without clients and closes the clients
+// of hosts that are neither nodes nor owners of vnodes.
+func (p *Pool[T]) sync() {
+	live := make(map[string]struct{})
+	for _, addr := range p.dist.Nodes() {
+		live[addr] = struct{}{}
+	}
+	p.dist.EachVNode(func(_ uint64, addr string) bool {
+		live[addr] = struct{}{}
+		return true
+	})
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	for addr, client := range p.clients {
+		if _, ok := live[addr]; !ok {
+			_ = p.close(client)
+			delete(p.clients, addr)
+		}
+	}
+	for addr := range live {
+		if _, ok := p.clients[addr]; ok {
+			continue
+		}
+		if client, err := p.dial(addr); err == nil {
+			p.clients[addr] = client
+		}
+	}
+}
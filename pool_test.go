@@ -0,0 +1,101 @@
+package h3geodist
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uber/h3-go/v3"
+)
+
+type testClient struct {
+	addr   string
+	mu     sync.Mutex
+	closed bool
+}
+
+func (c *testClient) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func TestPool(t *testing.T) {
+	h3dist, err := New(Level3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{"host-a.com", "host-b.com"} {
+		if err := h3dist.Add(addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool := NewPool(h3dist, func(addr string) (*testClient, error) {
+		if addr == "host-down.com" {
+			return nil, errors.New("dial failed")
+		}
+		return &testClient{addr: addr}, nil
+	}, func(c *testClient) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.closed = true
+		return nil
+	})
+	if have, want := pool.Hosts(), 2; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	cell := h3.FromGeo(h3.GeoCoord{Latitude: 52.52, Longitude: 13.405}, Level3)
+	owner, _ := h3dist.Lookup(cell)
+	client, err := pool.For(cell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := client.addr, owner.Host; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+	for _, child := range h3.ToChildren(cell, Level3+2) {
+		fine, err := pool.For(child)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fine != client {
+			t.Fatalf("have %s, want %s", fine.addr, client.addr)
+		}
+	}
+	if _, err := pool.For(h3.ToParent(cell, Level1)); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+
+	if err := h3dist.Add("host-c.com"); err != nil {
+		t.Fatal(err)
+	}
+	h3dist.Remove(owner.Host)
+	deadline := time.Now().Add(5 * time.Second)
+	for !client.isClosed() || pool.Hosts() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("have %d hosts, want 2 with %s closed", pool.Hosts(), owner.Host)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	next, err := pool.For(cell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.addr == owner.Host {
+		t.Fatalf("have %s, want another host", next.addr)
+	}
+	if _, err := pool.Get("host-down.com"); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !next.isClosed() {
+		t.Fatalf("have open client, want closed")
+	}
+	if _, err := pool.For(cell); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("have %v, want %v", err, ErrPoolClosed)
+	}
+}
@@ -0,0 +1,172 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/uber/h3-go/v3"
+)
+
+var (
+	// ErrNoKey returns when the request carries no location or cell.
+	ErrNoKey = errors.New("proxy: no key in request")
+
+	// ErrCellLevel returns when the cell is coarser than the distribution level.
+	ErrCellLevel = errors.New("proxy: cell coarser than distribution level")
+)
+
+// Key is a type to represent the routing key of a request:
+// a cell or a geographic coordinate.
+type Key struct {
+	Cell h3.H3Index
+	Lat  float64
+	Lon  float64
+	// LatLon is TRUE if the key is the coordinate instead of the cell.
+	LatLon bool
+}
+
+// Extractor extracts the routing key from the request.
+// It returns ErrNoKey if the request carries no key.
+type Extractor func(r *http.Request) (Key, error)
+
+// Query extracts the coordinate from the query parameters.
+func Query(latParam, lonParam string) Extractor {
+	return func(r *http.Request) (Key, error) {
+		q := r.URL.Query()
+		return ParseLatLon(q.Get(latParam), q.Get(lonParam))
+	}
+}
+
+// QueryCell extracts the hex cell from the query parameter.
+func QueryCell(param string) Extractor {
+	return func(r *http.Request) (Key, error) {
+		return ParseCell(r.URL.Query().Get(param))
+	}
+}
+
+// Header extracts the coordinate from the headers.
+func Header(latHeader, lonHeader string) Extractor {
+	return func(r *http.Request) (Key, error) {
+		return ParseLatLon(r.Header.Get(latHeader), r.Header.Get(lonHeader))
+	}
+}
+
+// HeaderCell extracts the hex cell from the header.
+func HeaderCell(name string) Extractor {
+	return func(r *http.Request) (Key, error) {
+		return ParseCell(r.Header.Get(name))
+	}
+}
+
+// Path extracts the hex cell from the path segment by the index,
+// for example index 1 of /cells/8928308280fffff/objects.
+func Path(index int) Extractor {
+	return func(r *http.Request) (Key, error) {
+		segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if index < 0 || index >= len(segments) {
+			return Key{}, ErrNoKey
+		}
+		return ParseCell(segments[index])
+	}
+}
+
+// JSONBody extracts the coordinate from the top-level fields of the JSON body.
+// The body is restored for forwarding.
+func JSONBody(latField, lonField string) Extractor {
+	return func(r *http.Request) (Key, error) {
+		fields, err := bodyFields(r)
+		if err != nil {
+			return Key{}, err
+		}
+		lat, latOK := fields[latField]
+		lon, lonOK := fields[lonField]
+		if !latOK || !lonOK {
+			return Key{}, ErrNoKey
+		}
+		return ParseLatLon(strings.Trim(string(lat), `"`), strings.Trim(string(lon), `"`))
+	}
+}
+
+// JSONBodyCell extracts the hex cell from the top-level field of the JSON body.
+// The body is restored for forwarding.
+func JSONBodyCell(field string) Extractor {
+	return func(r *http.Request) (Key, error) {
+		fields, err := bodyFields(r)
+		if err != nil {
+			return Key{}, err
+		}
+		var cell string
+		if raw, ok := fields[field]; !ok || json.Unmarshal(raw, &cell) != nil {
+			return Key{}, ErrNoKey
+		}
+		return ParseCell(cell)
+	}
+}
+
+// First tries the extractors in order and returns the first found key.
+func First(extractors ...Extractor) Extractor {
+	return func(r *http.Request) (Key, error) {
+		for _, extract := range extractors {
+			key, err := extract(r)
+			if errors.Is(err, ErrNoKey) {
+				continue
+			}
+			return key, err
+		}
+		return Key{}, ErrNoKey
+	}
+}
+
+// ParseLatLon returns the key of the decimal coordinate,
+// ErrNoKey if any part is empty.
+func ParseLatLon(lat, lon string) (Key, error) {
+	if lat == "" || lon == "" {
+		return Key{}, ErrNoKey
+	}
+	la, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return Key{}, fmt.Errorf("proxy: invalid latitude %q", lat)
+	}
+	lo, err := strconv.ParseFloat(lon, 64)
+	if err != nil {
+		return Key{}, fmt.Errorf("proxy: invalid longitude %q", lon)
+	}
+	return Key{Lat: la, Lon: lo, LatLon: true}, nil
+}
+
+// ParseCell returns the key of the hex cell, ErrNoKey if it is empty.
+func ParseCell(s string) (Key, error) {
+	if s == "" {
+		return Key{}, ErrNoKey
+	}
+	cell := h3.FromString(s)
+	if !h3.IsValid(cell) {
+		return Key{}, fmt.Errorf("proxy: invalid cell %q", s)
+	}
+	return Key{Cell: cell}, nil
+}
+
+// bodyFields decodes the top-level fields of the JSON body
+// and restores the body.
+func bodyFields(r *http.Request) (map[string]json.RawMessage, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, ErrNoKey
+	}
+	data, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, ErrNoKey
+	}
+	return fields, nil
+}
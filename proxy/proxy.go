@@ -0,0 +1,207 @@
+// Package proxy provides the http.Handler forwarding requests
+// to the host owning the location of the request:
+//
+//	h := proxy.New(h3dist, proxy.First(
+//		proxy.Query("lat", "lon"),
+//		proxy.QueryCell("cell"),
+//	), proxy.WithReplicas(1))
+//	http.ListenAndServe(":8080", h)
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+
+	"github.com/mmadfox/go-h3geo-dist"
+	"github.com/uber/h3-go/v3"
+)
+
+// CellHeader is the header carrying the hex cell of the forwarded request.
+const CellHeader = "X-H3-Cell"
+
+// Option is a type to represent the proxy configuration.
+type Option func(*Proxy)
+
+// WithReplicas sets the number of replica hosts tried
+// after the owner fails, see Distributed.ReplicaFor.
+func WithReplicas(n int) Option {
+	return func(p *Proxy) {
+		if n >= 0 {
+			p.replicas = n
+		}
+	}
+}
+
+// WithScheme sets the scheme of forwarded requests, http by default.
+func WithScheme(scheme string) Option {
+	return func(p *Proxy) {
+		p.scheme = scheme
+	}
+}
+
+// WithTransport sets the transport of forwarded requests,
+// http.DefaultTransport by default.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(p *Proxy) {
+		p.transport = transport
+	}
+}
+
+// WithErrorLog sets the logger of failed forwards,
+// the standard logger by default.
+func WithErrorLog(logger *log.Logger) Option {
+	return func(p *Proxy) {
+		p.errorLog = logger
+	}
+}
+
+// Proxy is the reverse proxy routing requests by location.
+// Thread-safe.
+type Proxy struct {
+	dist      *h3geodist.Distributed
+	key       Extractor
+	replicas  int
+	scheme    string
+	transport http.RoundTripper
+	errorLog  *log.Logger
+}
+
+// New creates and returns a new proxy of the distribution.
+func New(d *h3geodist.Distributed, key Extractor, opts ...Option) *Proxy {
+	p := &Proxy{
+		dist:   d,
+		key:    key,
+		scheme: "http",
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// ServeHTTP forwards the request to the owner of the key,
+// then to the replica hosts in order while forwarding fails.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, err := p.key(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cell, hosts, err := p.route(key)
+	switch {
+	case errors.Is(err, ErrCellLevel):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, h3geodist.ErrOutOfCoverage):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	var body []byte
+	if len(hosts) > 1 && r.Body != nil && r.Body != http.NoBody {
+		// keep the body to replay it to the next host
+		body, err = io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	for i, host := range hosts {
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		if p.forward(w, r, cell, host, i == len(hosts)-1) {
+			return
+		}
+	}
+}
+
+// route returns the cell of the key and the hosts to try.
+// The owner goes first. A draining owner is not among the nodes,
+// so its replicas are not known and only the owner is tried.
+func (p *Proxy) route(key Key) (h3.H3Index, []string, error) {
+	c, err := Lookup(p.dist, key)
+	if err != nil {
+		return 0, nil, err
+	}
+	n := p.replicas + 1
+	if nodes := len(p.dist.Nodes()); n > nodes {
+		n = nodes
+	}
+	if n <= 1 {
+		return c.H3ID, []string{c.Host}, nil
+	}
+	hosts, err := p.dist.ReplicaFor(c.H3ID, n)
+	if err != nil || hosts[0] != c.Host {
+		return c.H3ID, []string{c.Host}, nil
+	}
+	return c.H3ID, hosts, nil
+}
+
+// Lookup returns the distributed cell of the key.
+// A cell finer than the level resolves to its parent distributed cell,
+// so it routes the same way as the coordinate within it.
+// It returns ErrCellLevel for a cell coarser than the level,
+// h3geodist.ErrOutOfCoverage for a key outside the coverage
+// and h3geodist.ErrVNodes if there are no nodes.
+func Lookup(d *h3geodist.Distributed, key Key) (h3geodist.Cell, error) {
+	if key.LatLon {
+		return d.LookupFromLatLon(key.Lat, key.Lon)
+	}
+	switch res := h3.Resolution(key.Cell); {
+	case res < d.Level():
+		return h3geodist.Cell{}, ErrCellLevel
+	case res > d.Level():
+		c, err := d.WhereIsMyParent(key.Cell)
+		if err == nil || errors.Is(err, h3geodist.ErrOutOfCoverage) || errors.Is(err, h3geodist.ErrVNodes) {
+			return c, err
+		}
+		// a cell coarser than the split level of a hot cell
+		// resolves to its shard by Lookup
+	}
+	if !d.Covers(key.Cell) {
+		return h3geodist.Cell{}, h3geodist.ErrOutOfCoverage
+	}
+	c, ok := d.Lookup(key.Cell)
+	if !ok {
+		return h3geodist.Cell{}, h3geodist.ErrVNodes
+	}
+	return c, nil
+}
+
+// forward proxies the request to the host and returns FALSE
+// if the host failed before the response was written.
+// The last host writes the failure as 502 Bad Gateway.
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request, cell h3.H3Index, host string, last bool) bool {
+	ok := true
+	rp := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = p.scheme
+			req.URL.Host = host
+			req.Header.Set(CellHeader, h3.ToString(cell))
+		},
+		Transport: p.transport,
+		ErrorLog:  p.errorLog,
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			if p.errorLog != nil {
+				p.errorLog.Printf("proxy: forward to %s: %v", host, err)
+			} else {
+				log.Printf("proxy: forward to %s: %v", host, err)
+			}
+			if last {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			ok = false
+		},
+	}
+	rp.ServeHTTP(w, r)
+	return ok
+}
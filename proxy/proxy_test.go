@@ -0,0 +1,238 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mmadfox/go-h3geo-dist"
+	"github.com/uber/h3-go/v3"
+)
+
+const (
+	lat = 52.5200
+	lon = 13.4050
+)
+
+func newBackends(t *testing.T, n int) (*h3geodist.Distributed, map[string]*httptest.Server) {
+	h3dist, err := h3geodist.New(h3geodist.Level5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backends := make(map[string]*httptest.Server, n)
+	for i := 0; i < n; i++ {
+		var addr string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("X-Backend", addr)
+			w.Header().Set(CellHeader, r.Header.Get(CellHeader))
+			_, _ = w.Write(body)
+		}))
+		addr = srv.Listener.Addr().String()
+		backends[addr] = srv
+		t.Cleanup(srv.Close)
+		if err := h3dist.Add(addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return h3dist, backends
+}
+
+func TestProxy_Route(t *testing.T) {
+	h3dist, _ := newBackends(t, 3)
+	owner, err := h3dist.LookupFromLatLon(lat, lon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cell := h3.ToString(owner.H3ID)
+	p := New(h3dist, First(
+		Query("lat", "lon"),
+		QueryCell("cell"),
+		Header("X-Lat", "X-Lon"),
+		HeaderCell("X-Cell"),
+		JSONBody("lat", "lon"),
+		JSONBodyCell("cell"),
+		Path(1),
+	))
+	requests := map[string]*http.Request{
+		"query":      httptest.NewRequest(http.MethodGet, "/?lat=52.52&lon=13.405", nil),
+		"query cell": httptest.NewRequest(http.MethodGet, "/?cell="+cell, nil),
+		"json":       httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"lat":52.52,"lon":"13.405"}`)),
+		"json cell":  httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"cell":"`+cell+`"}`)),
+		"path":       httptest.NewRequest(http.MethodGet, "/cells/"+cell+"/objects", nil),
+	}
+	requests["header"] = httptest.NewRequest(http.MethodGet, "/", nil)
+	requests["header"].Header.Set("X-Lat", "52.52")
+	requests["header"].Header.Set("X-Lon", "13.405")
+	requests["header cell"] = httptest.NewRequest(http.MethodGet, "/", nil)
+	requests["header cell"].Header.Set("X-Cell", cell)
+	for name, r := range requests {
+		var body []byte
+		if r.Body != nil {
+			body, _ = io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		if have, want := w.Code, http.StatusOK; have != want {
+			t.Fatalf("%s: have %d, want %d", name, have, want)
+		}
+		if have, want := w.Header().Get("X-Backend"), owner.Host; have != want {
+			t.Fatalf("%s: have %s, want %s", name, have, want)
+		}
+		if have, want := w.Header().Get(CellHeader), cell; have != want {
+			t.Fatalf("%s: have %s, want %s", name, have, want)
+		}
+		if have, want := w.Body.String(), string(body); have != want {
+			t.Fatalf("%s: have %s, want %s", name, have, want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if have, want := w.Code, http.StatusBadRequest; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?lat=north&lon=1", nil))
+	if have, want := w.Code, http.StatusBadRequest; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+}
+
+func TestProxy_Fallback(t *testing.T) {
+	h3dist, backends := newBackends(t, 3)
+	owner, err := h3dist.LookupFromLatLon(lat, lon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replicas, err := h3dist.ReplicaFor(owner.H3ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backends[owner.Host].Close()
+	target := "/?" + url.Values{"lat": {"52.52"}, "lon": {"13.405"}}.Encode()
+	logger := log.New(io.Discard, "", 0)
+
+	p := New(h3dist, Query("lat", "lon"), WithReplicas(1), WithErrorLog(logger))
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader("payload")))
+	if have, want := w.Code, http.StatusOK; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if have, want := w.Header().Get("X-Backend"), replicas[1]; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+	if have, want := w.Body.String(), "payload"; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+
+	p = New(h3dist, Query("lat", "lon"), WithErrorLog(logger))
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	if have, want := w.Code, http.StatusBadGateway; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+}
+
+func TestProxy_NoNodes(t *testing.T) {
+	h3dist, err := h3geodist.New(h3geodist.Level5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(h3dist, Query("lat", "lon"))
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?lat=52.52&lon=13.405", nil))
+	if have, want := w.Code, http.StatusServiceUnavailable; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+}
+
+func TestLookup_CellLevel(t *testing.T) {
+	h3dist, err := h3geodist.New(h3geodist.Level3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{"host-a.com", "host-b.com", "host-c.com", "host-d.com", "host-e.com"} {
+		if err := h3dist.Add(addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var mismatches int
+	for i := 0; i < 200; i++ {
+		la, lo := -60+float64(i)*0.6, -180+float64(i)*1.8
+		fine := h3.FromGeo(h3.GeoCoord{Latitude: la, Longitude: lo}, 9)
+		byCell, err := Lookup(h3dist, Key{Cell: fine})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent, _ := h3dist.Lookup(h3.ToParent(fine, h3geodist.Level3))
+		if byCell != parent {
+			t.Fatalf("have %v, want %v", byCell, parent)
+		}
+		byLatLon, err := Lookup(h3dist, Key{Lat: la, Lon: lo, LatLon: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if byCell.Host != byLatLon.Host {
+			mismatches++
+		}
+	}
+	// children don't nest exactly, so only points near cell edges differ
+	if mismatches > 20 {
+		t.Fatalf("have %d mismatches, want <= 20", mismatches)
+	}
+
+	coarse := h3.FromGeo(h3.GeoCoord{Latitude: lat, Longitude: lon}, h3geodist.Level1)
+	if _, err := Lookup(h3dist, Key{Cell: coarse}); !errors.Is(err, ErrCellLevel) {
+		t.Fatalf("have %v, want %v", err, ErrCellLevel)
+	}
+	p := New(h3dist, QueryCell("cell"))
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?cell="+h3.ToString(coarse), nil))
+	if have, want := w.Code, http.StatusBadRequest; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+}
+
+func TestProxy_Draining(t *testing.T) {
+	h3dist, err := h3geodist.New(h3geodist.Level5, h3geodist.WithStepSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		var addr string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Backend", addr)
+		}))
+		addr = srv.Listener.Addr().String()
+		t.Cleanup(srv.Close)
+		if err := h3dist.Add(addr); err != nil {
+			t.Fatal(err)
+		}
+		for !h3dist.Progress().Done() {
+			h3dist.Step()
+		}
+	}
+	owner, err := h3dist.LookupFromLatLon(lat, lon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h3dist.Remove(owner.Host)
+	// the removed owner keeps serving until its vnodes are moved
+	if c, _ := h3dist.LookupFromLatLon(lat, lon); c.Host != owner.Host {
+		t.Fatalf("have %s, want %s", c.Host, owner.Host)
+	}
+	p := New(h3dist, Query("lat", "lon"), WithReplicas(1))
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?lat=52.52&lon=13.405", nil))
+	if have, want := w.Header().Get("X-Backend"), owner.Host; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+}
@@ -0,0 +1,239 @@
+package h3geodist
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxMoves  = 8
+	DefaultCooldown  = 5 * time.Minute
+	DefaultTolerance = 1.1
+)
+
+// Move is a type to represent a move of the vnode between hosts.
+type Move struct {
+	VNode uint64
+	From  string
+	To    string
+	// Load is the measured load of the vnode.
+	Load float64
+}
+
+// Rebalancer moves vnodes from overloaded to underloaded hosts
+// by the measured load of vnodes. Moved vnodes stay on their hosts
+// until the host is removed.
+// Thread-safe.
+type Rebalancer struct {
+	mu        sync.Mutex
+	d         *Distributed
+	maxMoves  int
+	cooldown  time.Duration
+	tolerance float64
+	now       func() time.Time
+	last      time.Time
+}
+
+// RebalancerOption is a type to represent various Rebalancer options.
+type RebalancerOption func(*Rebalancer)
+
+// WithMaxMoves sets the maximum number of moves per round. Default 8.
+func WithMaxMoves(val int) RebalancerOption {
+	return func(r *Rebalancer) {
+		r.maxMoves = val
+	}
+}
+
+// WithCooldown sets the minimal interval between applied rounds. Default 5m.
+func WithCooldown(val time.Duration) RebalancerOption {
+	return func(r *Rebalancer) {
+		r.cooldown = val
+	}
+}
+
+// WithTolerance sets the ratio of the host load to the average load
+// below which the host is not rebalanced. Default 1.1.
+func WithTolerance(val float64) RebalancerOption {
+	return func(r *Rebalancer) {
+		r.tolerance = val
+	}
+}
+
+// NewRebalancer creates and returns a new Rebalancer instance
+// for the distribution with specified options.
+func NewRebalancer(d *Distributed, opts ...RebalancerOption) *Rebalancer {
+	r := &Rebalancer{
+		d:         d,
+		maxMoves:  DefaultMaxMoves,
+		cooldown:  DefaultCooldown,
+		tolerance: DefaultTolerance,
+		now:       time.Now,
+	}
+	for _, f := range opts {
+		f(r)
+	}
+	return r
+}
+
+// Plan proposes the moves of a single round without applying them.
+// The load holds the measured load of each vnode, if nil
+// the lookups counted by WithTraffic are used.
+func (r *Rebalancer) Plan(load []float64) ([]Move, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if load == nil {
+		load = r.d.TrafficReport().VNodes
+	}
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+	return r.plan(load)
+}
+
+// Apply proposes and applies the moves of a single round,
+// so that data can follow the returned moves.
+// The round is planned and applied atomically with a single topology change.
+// It returns ErrCooldown if the previous round was applied within the cooldown.
+func (r *Rebalancer) Apply(load []float64) ([]Move, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if !r.last.IsZero() && now.Sub(r.last) < r.cooldown {
+		return nil, ErrCooldown
+	}
+	if load == nil {
+		load = r.d.TrafficReport().VNodes
+	}
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+	defer r.d.publish(r.d.state())
+	moves, err := r.plan(load)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range moves {
+		r.d.moveVNode(m.VNode, r.d.node(m.To))
+	}
+	if len(moves) > 0 {
+		r.last = now
+	}
+	return moves, nil
+}
+
+// plan proposes the moves by the load.
+// It must be called with the lock held.
+func (r *Rebalancer) plan(load []float64) ([]Move, error) {
+	if uint64(len(load)) != r.d.vnodes {
+		return nil, ErrNoLoad
+	}
+	hosts := make(map[string]float64, len(r.d.nodes))
+	owned := make(map[string][]uint64, len(r.d.nodes))
+	for _, n := range r.d.nodes {
+		hosts[n.addr] = 0
+	}
+	var total float64
+	for vnode := uint64(0); vnode < r.d.vnodes; vnode++ {
+		n, ok := r.d.index[int(vnode)]
+		if !ok {
+			continue
+		}
+		// a removed node is drained in the throttled mode
+		if _, ok := hosts[n.addr]; !ok {
+			continue
+		}
+		hosts[n.addr] += load[vnode]
+		owned[n.addr] = append(owned[n.addr], vnode)
+		total += load[vnode]
+	}
+	if len(hosts) < 2 || total <= 0 {
+		return nil, nil
+	}
+	avg := total / float64(len(hosts))
+	moves := make([]Move, 0, r.maxMoves)
+	for len(moves) < r.maxMoves {
+		from, to := extremeHosts(hosts)
+		if hosts[from] <= avg*r.tolerance {
+			break
+		}
+		// the vnode closest to a half of the gap reduces the max load most
+		gap := hosts[from] - hosts[to]
+		best, bestDiff := -1, gap/2
+		for i, vnode := range owned[from] {
+			if load[vnode] <= 0 || load[vnode] >= gap {
+				continue
+			}
+			diff := load[vnode] - gap/2
+			if diff < 0 {
+				diff = -diff
+			}
+			if best < 0 || diff < bestDiff {
+				best, bestDiff = i, diff
+			}
+		}
+		if best < 0 {
+			break
+		}
+		vnode := owned[from][best]
+		owned[from] = append(owned[from][:best], owned[from][best+1:]...)
+		owned[to] = append(owned[to], vnode)
+		hosts[from] -= load[vnode]
+		hosts[to] += load[vnode]
+		moves = append(moves, Move{VNode: vnode, From: from, To: to, Load: load[vnode]})
+	}
+	return moves, nil
+}
+
+// extremeHosts returns the most and the least loaded hosts.
+func extremeHosts(hosts map[string]float64) (hottest, coldest string) {
+	names := make([]string, 0, len(hosts))
+	for host := range hosts {
+		names = append(names, host)
+	}
+	sort.Strings(names)
+	hottest, coldest = names[0], names[0]
+	for _, host := range names[1:] {
+		if hosts[host] > hosts[hottest] {
+			hottest = host
+		}
+		if hosts[host] < hosts[coldest] {
+			coldest = host
+		}
+	}
+	return
+}
+
+// MoveVNode moves the vnode to the host.
+// The vnode stays on the host until the host is removed.
+func (d *Distributed) MoveVNode(vnode uint64, addr string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.publish(d.state())
+	if vnode >= d.vnodes {
+		return fmt.Errorf("h3geodist: vnode got %d, expected < %d", vnode, d.vnodes)
+	}
+	to := d.node(addr)
+	if to == nil {
+		return ErrNodeNotFound
+	}
+	d.moveVNode(vnode, to)
+	return nil
+}
+
+// moveVNode pins the vnode to the node.
+// It must be called with the write lock held.
+func (d *Distributed) moveVNode(vnode uint64, to *node) {
+	if d.moved == nil {
+		d.moved = make(map[uint64]string)
+	}
+	if from, ok := d.index[int(vnode)]; ok {
+		weight := d.vnodeWeight(vnode)
+		d.stats[from.addr] -= weight
+		d.stats[to.addr] += weight
+	}
+	d.index[int(vnode)] = to
+	if d.target != nil {
+		d.target[int(vnode)] = to
+	}
+	d.moved[vnode] = to.addr
+}
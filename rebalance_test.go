@@ -0,0 +1,105 @@
+package h3geodist
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRebalancer_Apply(t *testing.T) {
+	h3dist, err := New(Level3, WithVNodes(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		_ = h3dist.Add(fmt.Sprintf("host-%d.com", i))
+	}
+	// the vnodes of host-0.com are ten times hotter
+	load := make([]float64, h3dist.VNodes())
+	hostLoad := func() map[string]float64 {
+		res := make(map[string]float64)
+		for vnode := range load {
+			res[h3dist.index[vnode].addr] += load[vnode]
+		}
+		return res
+	}
+	for vnode := range load {
+		load[vnode] = 1
+		if h3dist.index[vnode].addr == "host-0.com" {
+			load[vnode] = 10
+		}
+	}
+	before := hostLoad()
+
+	now := time.Unix(0, 0)
+	r := NewRebalancer(h3dist, WithMaxMoves(2), WithCooldown(time.Minute))
+	r.now = func() time.Time { return now }
+
+	moves, err := r.Plan(load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(moves), 2; have != want {
+		t.Fatalf("have %d, want %d moves", have, want)
+	}
+	for _, m := range moves {
+		if m.From != "host-0.com" || m.To == m.From || m.Load != 10 {
+			t.Fatalf("unexpected move %+v", m)
+		}
+	}
+	// the plan is not applied
+	if have, want := hostLoad()["host-0.com"], before["host-0.com"]; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+
+	epoch := h3dist.Epoch()
+	applied, err := r.Apply(load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(applied), 2; have != want {
+		t.Fatalf("have %d, want %d moves", have, want)
+	}
+	// the round is a single topology change
+	if have, want := h3dist.Epoch(), epoch+1; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if have, want := hostLoad()["host-0.com"], before["host-0.com"]-20; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	for _, m := range applied {
+		if have, want := h3dist.index[int(m.VNode)].addr, m.To; have != want {
+			t.Fatalf("have %s, want %s", have, want)
+		}
+	}
+	if _, err := r.Apply(load); !errors.Is(err, ErrCooldown) {
+		t.Fatalf("have %v, want %v", err, ErrCooldown)
+	}
+
+	// moves survive the redistribution
+	_ = h3dist.Add("host-4.com")
+	for _, m := range applied {
+		if have, want := h3dist.index[int(m.VNode)].addr, m.To; have != want {
+			t.Fatalf("have %s, want %s", have, want)
+		}
+	}
+	var stats float64
+	for _, info := range h3dist.Stats() {
+		stats += info.Load
+	}
+	if have, want := stats, float64(h3dist.VNodes()); have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := r.Apply(load[:1]); !errors.Is(err, ErrNoLoad) {
+		t.Fatalf("have %v, want %v", err, ErrNoLoad)
+	}
+	if _, err := r.Apply(nil); !errors.Is(err, ErrNoLoad) {
+		t.Fatalf("have %v, want %v", err, ErrNoLoad)
+	}
+	if err := h3dist.MoveVNode(0, "unknown.com"); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("have %v, want %v", err, ErrNodeNotFound)
+	}
+}
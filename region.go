@@ -0,0 +1,71 @@
+package h3geodist
+
+import "github.com/uber/h3-go/v3"
+
+// WithRegion sets a finer level inside the polygon,
+// so that the region is sharded at its own resolution
+// while the rest of the globe keeps the distribution level.
+// Cells of the distribution level whose centers are within the polygon
+// are split into shards at the level, for a polygon smaller than a cell
+// the cells containing its vertices are split.
+// Overlapping regions take the finer level.
+// A level less than or equal to the distribution level is ignored,
+// New fails for a level more than MaxSplitDepth below the distribution level.
+func WithRegion(level int, polygon Polygon) Option {
+	return func(d *Distributed) {
+		if level <= d.level {
+			return
+		}
+		if d.regions == nil {
+			d.regions = make(map[h3.H3Index]int)
+		}
+		if d.hot == nil {
+			d.hot = make(map[h3.H3Index]int)
+		}
+		for _, cell := range regionCells(polygon, d.level) {
+			if level > d.regions[cell] {
+				d.regions[cell] = level
+				d.hot[cell] = level
+			}
+		}
+	}
+}
+
+// LevelAt returns the resolution of the distributed cell
+// for a geographic coordinate.
+func (d *Distributed) LevelAt(lat float64, lon float64) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	cell := h3.FromGeo(h3.GeoCoord{Latitude: lat, Longitude: lon}, d.level)
+	if split, ok := d.hot[cell]; ok {
+		return split
+	}
+	return d.level
+}
+
+// regionCells returns the cells at the level covering the polygon.
+func regionCells(polygon Polygon, level int) []h3.H3Index {
+	if len(polygon.Outer) < 3 {
+		return nil
+	}
+	gp := h3.GeoPolygon{Geofence: geoLoop(polygon.Outer)}
+	for _, hole := range polygon.Holes {
+		gp.Holes = append(gp.Holes, geoLoop(hole))
+	}
+	cells := h3.Polyfill(gp, level)
+	if len(cells) > 0 {
+		return cells
+	}
+	for _, ll := range polygon.Outer {
+		cells = append(cells, h3.FromGeo(ll.toGeo(), level))
+	}
+	return cells
+}
+
+func geoLoop(loop []LatLon) []h3.GeoCoord {
+	coords := make([]h3.GeoCoord, 0, len(loop))
+	for _, ll := range loop {
+		coords = append(coords, ll.toGeo())
+	}
+	return coords
+}
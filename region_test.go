@@ -0,0 +1,100 @@
+package h3geodist
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_WithRegion(t *testing.T) {
+	// a box around Paris
+	paris := Polygon{Outer: []LatLon{
+		{Lat: 47.5, Lon: 1}, {Lat: 47.5, Lon: 4}, {Lat: 50, Lon: 4}, {Lat: 50, Lon: 1},
+	}}
+	h3dist, err := New(Level2, WithVNodes(256),
+		WithRegion(Level4, paris),
+		WithRegion(Level1, paris),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		_ = h3dist.Add(fmt.Sprintf("host-%d.com", i))
+	}
+	regions := h3dist.HotCells()
+	if len(regions) == 0 {
+		t.Fatalf("have 0, want > 0 region cells")
+	}
+	if have, want := h3dist.LevelAt(48.8566, 2.3522), Level4; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if have, want := h3dist.LevelAt(-33.8688, 151.2093), Level2; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	c, err := h3dist.LookupFromLatLon(48.8566, 2.3522)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := h3.Resolution(c.H3ID), Level4; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	c, err = h3dist.LookupFromLatLon(-33.8688, 151.2093)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := h3.Resolution(c.H3ID), Level2; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	want := NumCells(Level2) + uint(len(regions))*(49-1)
+	var cells uint
+	h3dist.EachCell(func(c Cell) {
+		cells++
+	})
+	if have := cells; have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+	if have := uint(h3dist.DistributionReport().Cells); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+
+	// a region smaller than a cell splits the cell containing it
+	small := Polygon{Outer: []LatLon{
+		{Lat: 10, Lon: 10}, {Lat: 10, Lon: 10.01}, {Lat: 10.01, Lon: 10.01},
+	}}
+	h3dist, err = New(Level2, WithRegion(Level2+MaxSplitDepth, small))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := h3dist.LevelAt(10, 10), Level2+MaxSplitDepth; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	// the split depth is bounded
+	if _, err := New(Level2, WithRegion(Level2+MaxSplitDepth+1, small)); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+}
+
+func TestDistributed_UnmarkHotRegion(t *testing.T) {
+	paris := Polygon{Outer: []LatLon{
+		{Lat: 47.5, Lon: 1}, {Lat: 47.5, Lon: 4}, {Lat: 50, Lon: 4}, {Lat: 50, Lon: 1},
+	}}
+	h3dist, err := New(Level2, WithRegion(Level3, paris))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cell := h3.FromGeo(h3.GeoCoord{Latitude: 48.8566, Longitude: 2.3522}, Level2)
+	if err := h3dist.MarkHot(cell, Level5); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := h3dist.LevelAt(48.8566, 2.3522), Level5; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if err := h3dist.UnmarkHot(cell); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := h3dist.LevelAt(48.8566, 2.3522), Level3; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+}
@@ -0,0 +1,172 @@
+package h3geodist
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/uber/h3-go/v3"
+)
+
+const (
+	renderWidth     = 1440
+	renderHeight    = 720
+	legendRowHeight = 20
+)
+
+// RenderSVG writes a self-contained SVG map of the world to w,
+// with cells coloured by the owning host and a legend
+// with the number of cells per host.
+// A Distributed is rendered from its Snapshot.
+// Cells are drawn in an equirectangular projection as they are iterated.
+func RenderSVG(w io.Writer, l Layout) error {
+	bw := bufio.NewWriter(w)
+	newRenderer(l).svg(bw)
+	return bw.Flush()
+}
+
+// RenderHTML writes a self-contained HTML page with the SVG map
+// of the ownership layout to w. It does not refer to any external resources.
+func RenderHTML(w io.Writer, l Layout, title string) error {
+	bw := bufio.NewWriter(w)
+	title = html.EscapeString(title)
+	_, _ = fmt.Fprintf(bw, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n"+
+		"<title>%s</title>\n<style>body{font-family:sans-serif;margin:16px}svg{max-width:100%%;height:auto}</style>\n"+
+		"</head>\n<body>\n<h1>%s</h1>\n", title, title)
+	newRenderer(l).svg(bw)
+	_, _ = bw.WriteString("</body>\n</html>\n")
+	return bw.Flush()
+}
+
+type renderer struct {
+	layout Layout
+	hosts  []string
+	class  map[string]int
+	counts []int
+}
+
+// newRenderer renders a Distributed from its snapshot, so no lock is held
+// while writing and the legend holds the owners of cells, even draining ones.
+func newRenderer(l Layout) *renderer {
+	if d, ok := l.(*Distributed); ok {
+		l = d.Snapshot()
+	}
+	hosts := l.Nodes()
+	sort.Strings(hosts)
+	r := &renderer{
+		layout: l,
+		hosts:  hosts,
+		class:  make(map[string]int, len(hosts)),
+		counts: make([]int, len(hosts)),
+	}
+	for i, host := range hosts {
+		r.class[host] = i
+	}
+	return r
+}
+
+func (r *renderer) svg(w *bufio.Writer) {
+	height := renderHeight + legendRowHeight*(len(r.hosts)+1) + legendRowHeight/2
+	_, _ = fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		renderWidth, height, renderWidth, height)
+	_, _ = w.WriteString("<style>")
+	for i := range r.hosts {
+		_, _ = fmt.Fprintf(w, ".h%d{fill:%s}", i, hostColor(i))
+	}
+	_, _ = w.WriteString("text{font:12px sans-serif}</style>\n")
+	_, _ = fmt.Fprintf(w, `<clipPath id="map"><rect width="%d" height="%d"/></clipPath>`+"\n",
+		renderWidth, renderHeight)
+	_, _ = fmt.Fprintf(w, `<rect width="%d" height="%d" fill="#f4f4f4"/>`+"\n", renderWidth, renderHeight)
+	_, _ = w.WriteString(`<g clip-path="url(#map)">` + "\n")
+	r.layout.EachCell(func(c Cell) {
+		class, ok := r.class[c.Host]
+		if !ok {
+			return
+		}
+		r.counts[class]++
+		r.cell(w, class, c.H3ID)
+	})
+	_, _ = w.WriteString("</g>\n")
+	r.legend(w)
+	_, _ = w.WriteString("</svg>\n")
+}
+
+// cell draws the cell, cells crossing the antimeridian are drawn on both sides.
+func (r *renderer) cell(w *bufio.Writer, class int, cell h3.H3Index) {
+	boundary := h3.ToGeoBoundary(cell)
+	loop := make([]LatLon, 0, len(boundary)+2)
+	for i := 0; i < len(boundary); i++ {
+		ll := latLonFromGeo(boundary[i])
+		if i > 0 {
+			prev := loop[i-1].Lon
+			ll.Lon = prev + math.Remainder(ll.Lon-prev, 360)
+		}
+		loop = append(loop, ll)
+	}
+	first, last := loop[0], loop[len(loop)-1]
+	closing := last.Lon + math.Remainder(first.Lon-last.Lon, 360)
+	if math.Abs(closing-first.Lon) > 180 {
+		// the cell contains a pole, close the loop along it
+		pole := 90.0
+		if first.Lat < 0 {
+			pole = -90
+		}
+		loop = append(loop,
+			LatLon{Lat: first.Lat, Lon: closing},
+			LatLon{Lat: pole, Lon: closing},
+			LatLon{Lat: pole, Lon: first.Lon})
+	}
+	minLon, maxLon := loop[0].Lon, loop[0].Lon
+	for _, ll := range loop {
+		minLon = math.Min(minLon, ll.Lon)
+		maxLon = math.Max(maxLon, ll.Lon)
+	}
+	for offset := -360.0; offset <= 360; offset += 360 {
+		if maxLon+offset < -180 || minLon+offset > 180 {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, `<path class="h%d" d="`, class)
+		for i, ll := range loop {
+			if i == 0 {
+				_ = w.WriteByte('M')
+			} else {
+				_ = w.WriteByte('L')
+			}
+			x := (ll.Lon + offset + 180) / 360 * renderWidth
+			y := (90 - ll.Lat) / 180 * renderHeight
+			_, _ = w.WriteString(strconv.FormatFloat(x, 'f', 2, 64))
+			_ = w.WriteByte(' ')
+			_, _ = w.WriteString(strconv.FormatFloat(y, 'f', 2, 64))
+		}
+		_, _ = w.WriteString(`Z"/>` + "\n")
+	}
+}
+
+func (r *renderer) legend(w *bufio.Writer) {
+	var total int
+	for _, count := range r.counts {
+		total += count
+	}
+	for i, host := range r.hosts {
+		y := renderHeight + legendRowHeight*(i+1)
+		var share float64
+		if total > 0 {
+			share = float64(r.counts[i]) / float64(total) * 100
+		}
+		_, _ = fmt.Fprintf(w, `<rect class="h%d" x="8" y="%d" width="12" height="12"/>`, i, y-11)
+		_, _ = fmt.Fprintf(w, `<text x="28" y="%d">%s: %d cells (%.2f%%)</text>`+"\n",
+			y, html.EscapeString(host), r.counts[i], share)
+	}
+	_, _ = fmt.Fprintf(w, `<text x="8" y="%d">total: %d cells, level %d</text>`+"\n",
+		renderHeight+legendRowHeight*(len(r.hosts)+1), total, r.layout.Level())
+}
+
+// hostColor returns a distinct color for the host by its position in the legend.
+func hostColor(i int) string {
+	hue := math.Mod(float64(i)*137.508, 360)
+	return fmt.Sprintf("hsl(%.0f,65%%,55%%)", hue)
+}
@@ -0,0 +1,109 @@
+package h3geodist
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRenderSVG(t *testing.T) {
+	h3dist, err := New(Level1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"}
+	for _, host := range hosts {
+		_ = h3dist.Add(host)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderSVG(&buf, h3dist); err != nil {
+		t.Fatal(err)
+	}
+	var svg struct {
+		Group struct {
+			Paths []struct {
+				Class string `xml:"class,attr"`
+				D     string `xml:"d,attr"`
+			} `xml:"path"`
+		} `xml:"g"`
+		Texts []string `xml:"text"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &svg); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := uint(len(svg.Group.Paths)), NumCells(Level1); have < want {
+		t.Fatalf("have %d, want >= %d paths", have, want)
+	}
+	if have, want := len(svg.Texts), len(hosts)+1; have != want {
+		t.Fatalf("have %d, want %d legend rows", have, want)
+	}
+	stats := make(map[string]int)
+	h3dist.EachCell(func(c Cell) {
+		stats[c.Host]++
+	})
+	for i, host := range hosts {
+		if !strings.HasPrefix(svg.Texts[i], host+": ") {
+			t.Fatalf("have %s, want %s legend", svg.Texts[i], host)
+		}
+		if !strings.Contains(svg.Texts[i], " "+strconv.Itoa(stats[host])+" cells") {
+			t.Fatalf("have %s, want %d cells", svg.Texts[i], stats[host])
+		}
+	}
+
+	// rendering from a snapshot gives the same map
+	var fromSnapshot bytes.Buffer
+	if err := RenderSVG(&fromSnapshot, h3dist.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), fromSnapshot.Bytes()) {
+		t.Fatalf("have different maps from distributed and snapshot")
+	}
+}
+
+func TestRenderSVGDraining(t *testing.T) {
+	h3dist, err := New(Level1, WithStepSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = h3dist.Add("127.0.0.1")
+	_ = h3dist.Add("127.0.0.2")
+	for !h3dist.Progress().Done() {
+		h3dist.Step()
+	}
+	// the removed host keeps its cells until they are moved by Step
+	h3dist.Remove("127.0.0.2")
+	var buf bytes.Buffer
+	// the writer changes the topology while the map is rendered
+	w := funcWriter(func(p []byte) (int, error) {
+		h3dist.Step()
+		return buf.Write(p)
+	})
+	if err := RenderSVG(w, h3dist); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<text") || !strings.Contains(buf.String(), "127.0.0.2: ") {
+		t.Fatalf("have no legend of the draining host, want legend")
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	h3dist, _ := New(Level0)
+	_ = h3dist.Add("127.0.0.1")
+	var buf bytes.Buffer
+	if err := RenderHTML(&buf, h3dist, "staging <eu>"); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	if !strings.Contains(page, "<title>staging &lt;eu&gt;</title>") {
+		t.Fatalf("title not found")
+	}
+	if !strings.Contains(page, "<svg") {
+		t.Fatalf("svg not found")
+	}
+	if strings.Contains(page, "src=") || strings.Contains(page, "href=") {
+		t.Fatalf("have external resources, want self-contained page")
+	}
+}
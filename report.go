@@ -0,0 +1,119 @@
+package h3geodist
+
+import (
+	"math"
+	"sort"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// HostReport is a type to represent the share of a single host.
+type HostReport struct {
+	Host    string
+	Cells   int
+	VNodes  int
+	AreaKm2 float64
+	// Share is the percentage of cells owned by the host.
+	Share float64
+}
+
+// DistributionReport is a type to represent the distribution
+// of cells by hosts with the balance statistics.
+type DistributionReport struct {
+	Level   int
+	Cells   int
+	AreaKm2 float64
+	// Hosts is sorted by host.
+	Hosts []HostReport
+	// StdDev is the standard deviation of the number of cells per host.
+	StdDev float64
+	// MaxMinRatio is the ratio of the max to the min number of cells per host.
+	MaxMinRatio float64
+	// Gini is the Gini coefficient of the number of cells per host,
+	// 0 means perfect balance.
+	Gini float64
+}
+
+// DistributionReport returns the distribution of cells by hosts.
+// It iterates each cell at the level, which takes a while for Level5 and Level6.
+// Shards of hot cells are counted as cells.
+func (d *Distributed) DistributionReport() DistributionReport {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	r := DistributionReport{
+		Level: d.level,
+		Hosts: make([]HostReport, len(d.nodes)),
+	}
+	index := make(map[string]int, len(d.nodes))
+	for i, n := range d.nodes {
+		index[n.addr] = i
+		r.Hosts[i] = HostReport{Host: n.addr}
+	}
+	for _, n := range d.index {
+		// a removed node still serves vnodes in the throttled mode
+		if _, ok := index[n.addr]; !ok {
+			index[n.addr] = len(r.Hosts)
+			r.Hosts = append(r.Hosts, HostReport{Host: n.addr})
+		}
+		r.Hosts[index[n.addr]].VNodes++
+	}
+	if len(d.nodes) == 0 {
+		return r
+	}
+	d.partition().eachShard(func(cell h3.H3Index) {
+		addr, ok := d.lookup(cell)
+		if !ok {
+			return
+		}
+		area := CellAreaKm2(cell)
+		host := &r.Hosts[index[addr]]
+		host.Cells++
+		host.AreaKm2 += area
+		r.Cells++
+		r.AreaKm2 += area
+	})
+	sort.Slice(r.Hosts, func(i, j int) bool {
+		return r.Hosts[i].Host < r.Hosts[j].Host
+	})
+	cells := make([]float64, len(r.Hosts))
+	for i := range r.Hosts {
+		if r.Cells > 0 {
+			r.Hosts[i].Share = float64(r.Hosts[i].Cells) / float64(r.Cells) * 100
+		}
+		cells[i] = float64(r.Hosts[i].Cells)
+	}
+	r.StdDev, r.MaxMinRatio, r.Gini = balance(cells)
+	return r
+}
+
+// balance returns the standard deviation, max/min ratio
+// and Gini coefficient of the values.
+func balance(values []float64) (stddev, ratio, gini float64) {
+	if len(values) == 0 {
+		return
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / float64(len(sorted))
+	var weighted float64
+	for i, v := range sorted {
+		stddev += (v - mean) * (v - mean)
+		weighted += float64(i+1) * v
+	}
+	stddev = math.Sqrt(stddev / float64(len(sorted)))
+	n := float64(len(sorted))
+	if sum > 0 {
+		gini = 2*weighted/(n*sum) - (n+1)/n
+	}
+	switch lo, hi := sorted[0], sorted[len(sorted)-1]; {
+	case lo > 0:
+		ratio = hi / lo
+	case hi > 0:
+		ratio = math.Inf(1)
+	}
+	return
+}
@@ -0,0 +1,98 @@
+package h3geodist
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistributed_DistributionReport(t *testing.T) {
+	h3dist, err := New(Level3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := []string{"host-c.com", "host-a.com", "host-b.com"}
+	for _, host := range hosts {
+		_ = h3dist.Add(host)
+	}
+
+	r := h3dist.DistributionReport()
+	if have, want := uint(r.Cells), NumCells(Level3); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+	// surface area of the Earth
+	if want := 510065621.7; math.Abs(r.AreaKm2-want)/want > 1e-3 {
+		t.Fatalf("have %f, want %f km2", r.AreaKm2, want)
+	}
+	if have, want := len(r.Hosts), len(hosts); have != want {
+		t.Fatalf("have %d, want %d hosts", have, want)
+	}
+	stats := make(map[string]int)
+	h3dist.EachCell(func(c Cell) {
+		stats[c.Host]++
+	})
+	var share float64
+	for i, host := range r.Hosts {
+		if i > 0 && r.Hosts[i-1].Host >= host.Host {
+			t.Fatalf("hosts are not sorted")
+		}
+		if have, want := host.Cells, stats[host.Host]; have != want {
+			t.Fatalf("have %d, want %d cells", have, want)
+		}
+		if host.VNodes == 0 {
+			t.Fatalf("have 0, want > 0 vnodes")
+		}
+		share += host.Share
+	}
+	if math.Abs(share-100) > 1e-9 {
+		t.Fatalf("have %f, want 100 percent", share)
+	}
+	if r.StdDev <= 0 || r.MaxMinRatio < 1 || r.Gini <= 0 || r.Gini >= 1 {
+		t.Fatalf("have unexpected balance %+v", r)
+	}
+}
+
+func TestBalance(t *testing.T) {
+	stddev, ratio, gini := balance([]float64{10, 10, 10, 10})
+	if stddev != 0 || ratio != 1 || gini != 0 {
+		t.Fatalf("have %f %f %f, want 0 1 0", stddev, ratio, gini)
+	}
+	stddev, ratio, gini = balance([]float64{0, 0, 0, 12})
+	if have, want := gini, 0.75; math.Abs(have-want) > 1e-9 {
+		t.Fatalf("have %f, want %f gini", have, want)
+	}
+	if !math.IsInf(ratio, 1) {
+		t.Fatalf("have %f, want +Inf ratio", ratio)
+	}
+	if have, want := stddev, math.Sqrt(27); math.Abs(have-want) > 1e-9 {
+		t.Fatalf("have %f, want %f stddev", have, want)
+	}
+}
+
+func TestAvgCellAreaKm2(t *testing.T) {
+	for level := Level0; level <= Level6; level++ {
+		area := AvgCellAreaKm2(level)
+		total := area * float64(NumCells(level))
+		if want := 510065621.7; math.Abs(total-want)/want > 0.05 {
+			t.Fatalf("level %d: have %f, want %f km2", level, total, want)
+		}
+	}
+	if have, want := AvgCellAreaKm2(Level6+1), 0.0; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	if have, want := NumCells(Level6+1), uint(0); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+}
+
+func TestDistributed_StatsSorted(t *testing.T) {
+	h3dist := Default()
+	for _, host := range []string{"host-c.com", "host-a.com", "host-d.com", "host-b.com"} {
+		_ = h3dist.Add(host)
+	}
+	stats := h3dist.Stats()
+	for i := 1; i < len(stats); i++ {
+		if stats[i-1].Host >= stats[i].Host {
+			t.Fatalf("have %s before %s, want sorted", stats[i-1].Host, stats[i].Host)
+		}
+	}
+}
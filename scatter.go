@@ -0,0 +1,237 @@
+package h3geodist
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// PartialPolicy is a type to represent the handling of failed hosts
+// in ScatterGather.
+type PartialPolicy int
+
+const (
+	// FailFast cancels the remaining calls on the first failed host
+	// and returns its error.
+	FailFast PartialPolicy = iota
+	// AllowPartial returns the results of all hosts with the errors
+	// of failed hosts in HostResult.Err. The error is returned
+	// only if every host failed.
+	AllowPartial
+)
+
+// HostResult is a type to represent the result of the call to the host.
+type HostResult[T any] struct {
+	// Host is the host that answered, a replica if the owner failed.
+	Host string
+	// Owner is the host owning the cells.
+	Owner string
+	Cells []h3.H3Index
+	Value T
+	Err   error
+}
+
+// ScatterOption is a type to represent various ScatterGather options.
+type ScatterOption func(*scatter)
+
+type scatter struct {
+	concurrency int
+	retries     int
+	policy      PartialPolicy
+}
+
+// WithConcurrency sets the maximum number of concurrent calls,
+// unlimited by default.
+func WithConcurrency(n int) ScatterOption {
+	return func(s *scatter) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
+}
+
+// WithRetries sets the number of replica hosts tried
+// after the owner fails, see ReplicaFor.
+func WithRetries(n int) ScatterOption {
+	return func(s *scatter) {
+		if n >= 0 {
+			s.retries = n
+		}
+	}
+}
+
+// WithPartialPolicy sets the handling of failed hosts, FailFast by default.
+func WithPartialPolicy(policy PartialPolicy) ScatterOption {
+	return func(s *scatter) {
+		s.policy = policy
+	}
+}
+
+// ScatterGather groups the cells by the owning host and calls fn
+// for each host with its distributed cells concurrently,
+// until the context is done. Cells coarser than the level are split
+// into their children, cells finer than the level are replaced
+// by their parents, so fine polyfills group by the owning cells.
+// Hot cells are split into their shards, cells outside the coverage are skipped.
+// Results are sorted by the owner.
+func ScatterGather[T any](
+	ctx context.Context,
+	d *Distributed,
+	cells []h3.H3Index,
+	fn func(ctx context.Context, host string, cells []h3.H3Index) (T, error),
+	opts ...ScatterOption,
+) ([]HostResult[T], error) {
+	s := scatter{}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	groups := d.groupCells(cells)
+	results := make([]HostResult[T], 0, len(groups))
+	for owner, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i] < group[j]
+		})
+		results = append(results, HostResult[T]{Owner: owner, Cells: group})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Owner < results[j].Owner
+	})
+	if len(results) == 0 {
+		return results, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	concurrency := s.concurrency
+	if concurrency == 0 || concurrency > len(results) {
+		concurrency = len(results)
+	}
+	sem := make(chan struct{}, concurrency)
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i := range results {
+		wg.Add(1)
+		go func(r *HostResult[T]) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				r.Host, r.Value, r.Err = call(ctx, d, s.retries, r.Owner, r.Cells, fn)
+			case <-ctx.Done():
+				r.Err = ctx.Err()
+			}
+			if r.Err != nil && s.policy == FailFast {
+				once.Do(func() {
+					firstErr = r.Err
+					cancel()
+				})
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+	if s.policy == FailFast {
+		return results, firstErr
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			return results, nil
+		}
+	}
+	return results, results[0].Err
+}
+
+// ScatterGatherPolygon calls fn for each host owning a part
+// of the polygon, see ScatterGather.
+func ScatterGatherPolygon[T any](
+	ctx context.Context,
+	d *Distributed,
+	polygon Polygon,
+	fn func(ctx context.Context, host string, cells []h3.H3Index) (T, error),
+	opts ...ScatterOption,
+) ([]HostResult[T], error) {
+	return ScatterGather(ctx, d, regionCells(polygon, d.Level()), fn, opts...)
+}
+
+// call calls fn for the owner, then for the replicas in order while it fails.
+func call[T any](
+	ctx context.Context,
+	d *Distributed,
+	retries int,
+	owner string,
+	cells []h3.H3Index,
+	fn func(ctx context.Context, host string, cells []h3.H3Index) (T, error),
+) (host string, value T, err error) {
+	hosts := []string{owner}
+	n := retries + 1
+	if nodes := len(d.Nodes()); n > nodes {
+		n = nodes
+	}
+	if n > 1 {
+		// replicas of the cells follow the owner, which may have moved since grouping
+		if replicas, rerr := d.ReplicaFor(cells[0], n); rerr == nil && replicas[0] == owner {
+			hosts = replicas
+		}
+	}
+	for _, host = range hosts {
+		value, err = fn(ctx, host, cells)
+		if err == nil || ctx.Err() != nil {
+			return host, value, err
+		}
+	}
+	return host, value, err
+}
+
+// groupCells returns the distributed cells of the cells grouped by the owner.
+// A cell finer than the level resolves to its parent, see WhereIsMyParent.
+func (d *Distributed) groupCells(cells []h3.H3Index) map[string][]h3.H3Index {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	p := d.partition()
+	groups := make(map[string][]h3.H3Index)
+	seen := make(map[h3.H3Index]struct{}, len(cells))
+	add := func(cell h3.H3Index) {
+		if !p.covers(cell) {
+			return
+		}
+		cell = p.shard(cell)
+		if _, ok := seen[cell]; ok {
+			return
+		}
+		seen[cell] = struct{}{}
+		if addr, ok := d.lookup(cell); ok && addr != "" {
+			groups[addr] = append(groups[addr], cell)
+		}
+	}
+	for _, cell := range cells {
+		res := h3.Resolution(cell)
+		if res < d.level {
+			for _, child := range h3.ToChildren(cell, d.level) {
+				if split, ok := p.hot[child]; ok {
+					for _, shard := range h3.ToChildren(child, split) {
+						add(shard)
+					}
+					continue
+				}
+				add(child)
+			}
+			continue
+		}
+		parent := h3.ToParent(cell, d.level)
+		split, ok := p.hot[parent]
+		switch {
+		case !ok:
+			add(parent)
+		case res >= split:
+			add(h3.ToParent(cell, split))
+		default:
+			for _, shard := range h3.ToChildren(cell, split) {
+				add(shard)
+			}
+		}
+	}
+	return groups
+}
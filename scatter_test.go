@@ -0,0 +1,181 @@
+package h3geodist
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestScatterGather(t *testing.T) {
+	h3dist, err := New(Level3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{"host-a.com", "host-b.com", "host-c.com"} {
+		if err := h3dist.Add(addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	region := h3.FromGeo(h3.GeoCoord{Latitude: 52.52, Longitude: 13.405}, Level1)
+	count := func(ctx context.Context, host string, cells []h3.H3Index) (int, error) {
+		for _, cell := range cells {
+			if c, ok := h3dist.Lookup(cell); !ok || c.Host != host {
+				return 0, errors.New("wrong host")
+			}
+		}
+		return len(cells), nil
+	}
+	counts, err := ScatterGather(context.Background(), h3dist, []h3.H3Index{region}, count, WithConcurrency(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	for _, r := range counts {
+		if r.Host != r.Owner {
+			t.Fatalf("have %s, want %s", r.Host, r.Owner)
+		}
+		total += r.Value
+	}
+	if have, want := total, len(h3.ToChildren(region, Level3)); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	// the failed owner is retried on the replica
+	failed := counts[0].Owner
+	flaky := func(ctx context.Context, host string, cells []h3.H3Index) (string, error) {
+		if host == failed {
+			return "", errors.New("host down")
+		}
+		return host, nil
+	}
+	_, err = ScatterGather(context.Background(), h3dist, []h3.H3Index{region}, flaky)
+	if err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	results, err := ScatterGather(context.Background(), h3dist, []h3.H3Index{region}, flaky, WithRetries(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replicas, err := h3dist.ReplicaFor(results[0].Cells[0], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := results[0].Value, replicas[1]; have != want {
+		t.Fatalf("have %s, want %s", have, want)
+	}
+
+	// partial results keep the answers of other hosts
+	var calls int32
+	down := func(ctx context.Context, host string, cells []h3.H3Index) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		if host == failed {
+			return "", errors.New("host down")
+		}
+		return host, nil
+	}
+	results, err = ScatterGather(context.Background(), h3dist, []h3.H3Index{region}, down,
+		WithPartialPolicy(AllowPartial))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := int(atomic.LoadInt32(&calls)), len(results); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	for _, r := range results {
+		if have, want := r.Err != nil, r.Owner == failed; have != want {
+			t.Fatalf("have %v, want error %v", r.Err, want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	blocked := func(ctx context.Context, host string, cells []h3.H3Index) (int, error) {
+		return 0, ctx.Err()
+	}
+	if _, err := ScatterGather(ctx, h3dist, []h3.H3Index{region}, blocked); !errors.Is(err, context.Canceled) {
+		t.Fatalf("have %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestScatterGatherPolygon(t *testing.T) {
+	h3dist, err := New(Level3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{"host-a.com", "host-b.com"} {
+		if err := h3dist.Add(addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	polygon := Polygon{Outer: []LatLon{
+		{Lat: 52.0, Lon: 13.0}, {Lat: 52.0, Lon: 14.0}, {Lat: 53.0, Lon: 14.0}, {Lat: 53.0, Lon: 13.0},
+	}}
+	results, err := ScatterGatherPolygon(context.Background(), h3dist, polygon,
+		func(ctx context.Context, host string, cells []h3.H3Index) (int, error) {
+			return len(cells), nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	for _, r := range results {
+		total += r.Value
+	}
+	if have, want := total, len(regionCells(polygon, Level3)); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+}
+
+func TestScatterGather_FineCells(t *testing.T) {
+	h3dist, err := New(Level3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{"host-a.com", "host-b.com", "host-c.com", "host-d.com", "host-e.com"} {
+		if err := h3dist.Add(addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	region := h3.FromGeo(h3.GeoCoord{Latitude: 52.52, Longitude: 13.405}, Level2)
+	fine := h3.ToChildren(region, 6)
+	results, err := ScatterGather(context.Background(), h3dist, fine,
+		func(ctx context.Context, host string, cells []h3.H3Index) (int, error) {
+			for _, cell := range cells {
+				if have, want := h3.Resolution(cell), Level3; have != want {
+					return 0, errors.New("cell is not distributed")
+				}
+				if c, ok := h3dist.Lookup(cell); !ok || c.Host != host {
+					return 0, errors.New("wrong host")
+				}
+			}
+			return len(cells), nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	for _, r := range results {
+		total += r.Value
+	}
+	if have, want := total, len(h3.ToChildren(region, Level3)); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	for _, cell := range fine[:50] {
+		c, err := h3dist.WhereIsMyParent(cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found bool
+		for _, r := range results {
+			for _, owned := range r.Cells {
+				found = found || (owned == c.H3ID && r.Owner == c.Host)
+			}
+		}
+		if !found {
+			t.Fatalf("have %x not grouped under %s", c.H3ID, c.Host)
+		}
+	}
+}
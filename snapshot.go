@@ -0,0 +1,109 @@
+package h3geodist
+
+import (
+	"sort"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// Layout is a source of the cell ownership layout.
+// It is implemented by Distributed and Snapshot.
+type Layout interface {
+	Level() int
+	Nodes() []string
+	EachCell(iter func(c Cell))
+}
+
+// Snapshot is a type to represent a serializable state
+// of the distribution with the owner of each vnode.
+type Snapshot struct {
+	CellLevel int      `json:"level"`
+	VNodes    uint64   `json:"vnodes"`
+	Owners    []string `json:"owners"`
+	// Hot holds the split levels of hot cells.
+	Hot map[h3.H3Index]int `json:"hot,omitempty"`
+	// Coverage holds the covered cells, empty means the whole globe.
+	Coverage []h3.H3Index `json:"coverage,omitempty"`
+}
+
+// Snapshot returns the current state of the distribution.
+func (d *Distributed) Snapshot() Snapshot {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	s := Snapshot{
+		CellLevel: d.level,
+		VNodes:    d.vnodes,
+		Owners:    make([]string, d.vnodes),
+	}
+	if len(d.hot) > 0 {
+		s.Hot = make(map[h3.H3Index]int, len(d.hot))
+		for cell, level := range d.hot {
+			s.Hot[cell] = level
+		}
+	}
+	if d.coverage != nil {
+		s.Coverage = append([]h3.H3Index(nil), d.coverage...)
+	}
+	for idx, n := range d.index {
+		s.Owners[idx] = n.addr
+	}
+	return s
+}
+
+func (s Snapshot) partition() partition {
+	return partition{level: s.CellLevel, hot: s.Hot, coverage: s.Coverage}
+}
+
+// Level returns the cell level of the distribution.
+func (d *Distributed) Level() int {
+	return d.level
+}
+
+// Level returns the cell level of the snapshot.
+func (s Snapshot) Level() int {
+	return s.CellLevel
+}
+
+// Nodes returns a sorted list of nodes owning at least one vnode.
+func (s Snapshot) Nodes() []string {
+	seen := make(map[string]struct{})
+	nodes := make([]string, 0, 4)
+	for _, addr := range s.Owners {
+		if _, ok := seen[addr]; ok || addr == "" {
+			continue
+		}
+		seen[addr] = struct{}{}
+		nodes = append(nodes, addr)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Lookup returns distributed cell.
+// A cell within a hot cell resolves to its shard.
+func (s Snapshot) Lookup(cell h3.H3Index) (Cell, bool) {
+	if s.VNodes == 0 || uint64(len(s.Owners)) != s.VNodes {
+		return Cell{}, false
+	}
+	p := s.partition()
+	if !p.covers(cell) {
+		return Cell{}, false
+	}
+	cell = p.shard(cell)
+	addr := s.Owners[uint2hash(uint64(cell))%s.VNodes]
+	if addr == "" {
+		return Cell{}, false
+	}
+	return Cell{H3ID: cell, Host: addr}, true
+}
+
+// EachCell iterate each distributed cell, calling fn for each cell.
+func (s Snapshot) EachCell(iter func(c Cell)) {
+	s.partition().eachShard(func(cell h3.H3Index) {
+		c, ok := s.Lookup(cell)
+		if !ok {
+			return
+		}
+		iter(c)
+	})
+}
@@ -0,0 +1,56 @@
+package h3geodist
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDistributed_Snapshot(t *testing.T) {
+	h3dist, err := New(Level2, WithVNodes(128))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = h3dist.Add("127.0.0.1")
+	_ = h3dist.Add("127.0.0.2")
+	_ = h3dist.Add("127.0.0.3")
+
+	data, err := json.Marshal(h3dist.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := snapshot.Level(), h3dist.Level(); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if have, want := len(snapshot.Nodes()), 3; have != want {
+		t.Fatalf("have %d, want %d nodes", have, want)
+	}
+	var cells int
+	snapshot.EachCell(func(c Cell) {
+		cells++
+		if !h3dist.IsOwned(c) {
+			t.Fatalf("h3dist.IsOwned(%v) => false, expected true", c)
+		}
+	})
+	if have, want := uint(cells), NumCells(Level2); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+}
+
+func TestSnapshot_Lookup(t *testing.T) {
+	var snapshot Snapshot
+	if _, ok := snapshot.Lookup(0); ok {
+		t.Fatalf("have true, want false")
+	}
+	snapshot = Default().Snapshot()
+	var cells int
+	snapshot.EachCell(func(c Cell) {
+		cells++
+	})
+	if have, want := cells, 0; have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+}
@@ -0,0 +1,90 @@
+package h3geodist
+
+import "sort"
+
+// WithStepSize enables the throttled rebalancing.
+// Add, Remove and other topology changes compute the target topology,
+// while the live index advances by at most n vnodes per Step.
+// A removed node keeps serving its vnodes until they are moved,
+// so hosts are drained and filled gradually.
+// The first node is added at once. A value less than or equal to 0
+// disables the throttling.
+func WithStepSize(n int) Option {
+	return func(d *Distributed) {
+		d.stepSize = n
+	}
+}
+
+// Progress is a type to represent the progress
+// of the live index towards the target topology.
+type Progress struct {
+	// Pending is the number of vnodes to move.
+	Pending int
+	// Hosts holds the number of vnodes to move by target hosts.
+	Hosts map[string]int
+}
+
+// Done returns TRUE if the live index matches the target topology.
+func (p Progress) Done() bool {
+	return p.Pending == 0
+}
+
+// Step moves at most the step size of vnodes towards the target topology
+// in ascending order of vnodes and returns the moves, so that data can follow.
+// Lookups reflect the moved vnodes right after the step.
+func (d *Distributed) Step() []Move {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.publish(d.state())
+	pending := d.pending()
+	if len(pending) > d.stepSize {
+		pending = pending[:d.stepSize]
+	}
+	moves := make([]Move, 0, len(pending))
+	for _, vnode := range pending {
+		m := Move{VNode: uint64(vnode), To: d.target[vnode].addr, Load: d.vnodeWeight(uint64(vnode))}
+		if from, ok := d.index[vnode]; ok {
+			m.From = from.addr
+		}
+		d.index[vnode] = d.target[vnode]
+		moves = append(moves, m)
+	}
+	if len(d.pending()) == 0 {
+		d.target = nil
+	}
+	d.stats = d.indexStats()
+	return moves
+}
+
+// Progress returns the progress of the live index towards the target topology.
+func (d *Distributed) Progress() Progress {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	p := Progress{Hosts: make(map[string]int)}
+	for _, vnode := range d.pending() {
+		p.Pending++
+		p.Hosts[d.target[vnode].addr]++
+	}
+	return p
+}
+
+// pending returns the sorted vnodes whose live node differs from the target.
+func (d *Distributed) pending() []int {
+	vnodes := make([]int, 0, len(d.target))
+	for vnode, n := range d.target {
+		if cur, ok := d.index[vnode]; !ok || cur.addr != n.addr {
+			vnodes = append(vnodes, vnode)
+		}
+	}
+	sort.Ints(vnodes)
+	return vnodes
+}
+
+// indexStats returns the load of nodes by the live index.
+func (d *Distributed) indexStats() map[string]float64 {
+	stats := make(map[string]float64)
+	for vnode, n := range d.index {
+		stats[n.addr] += d.vnodeWeight(uint64(vnode))
+	}
+	return stats
+}
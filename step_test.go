@@ -0,0 +1,102 @@
+package h3geodist
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDistributed_Step(t *testing.T) {
+	h3dist, err := New(Level3, WithVNodes(128), WithStepSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		_ = h3dist.Add(fmt.Sprintf("host-%d.com", i))
+	}
+	// the first node is added at once
+	for vnode := uint64(0); vnode < h3dist.VNodes(); vnode++ {
+		if have, want := h3dist.index[int(vnode)].addr, "host-0.com"; have != want {
+			t.Fatalf("have %s, want %s", have, want)
+		}
+	}
+	progress := h3dist.Progress()
+	if progress.Done() {
+		t.Fatalf("have done, want pending")
+	}
+	if have, want := progress.Hosts["host-0.com"], 0; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	total := progress.Pending
+
+	moves := h3dist.Step()
+	if have, want := len(moves), 10; have != want {
+		t.Fatalf("have %d, want %d moves", have, want)
+	}
+	for _, m := range moves {
+		if m.From != "host-0.com" || m.To == m.From || m.Load != 1 {
+			t.Fatalf("unexpected move %+v", m)
+		}
+		if have, want := h3dist.index[int(m.VNode)].addr, m.To; have != want {
+			t.Fatalf("have %s, want %s", have, want)
+		}
+	}
+	if have, want := h3dist.Progress().Pending, total-10; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	var load float64
+	for _, info := range h3dist.Stats() {
+		load += info.Load
+	}
+	if have, want := load, float64(h3dist.VNodes()); have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+
+	for steps := 1; !h3dist.Progress().Done(); steps++ {
+		if steps > total {
+			t.Fatalf("have > %d steps", total)
+		}
+		h3dist.Step()
+	}
+	if have := h3dist.Step(); len(have) != 0 {
+		t.Fatalf("have %d, want 0 moves", len(have))
+	}
+	// the target is the topology of the immediate mode
+	immediate, _ := New(Level3, WithVNodes(128))
+	for i := 0; i < 3; i++ {
+		_ = immediate.Add(fmt.Sprintf("host-%d.com", i))
+	}
+	for vnode := range immediate.index {
+		if have, want := h3dist.index[vnode].addr, immediate.index[vnode].addr; have != want {
+			t.Fatalf("have %s, want %s", have, want)
+		}
+	}
+
+	// a removed node is drained gradually
+	h3dist.Remove("host-2.com")
+	var draining int
+	h3dist.EachVNode(func(_ uint64, addr string) bool {
+		if addr == "host-2.com" {
+			draining++
+		}
+		return true
+	})
+	if draining == 0 {
+		t.Fatalf("have 0, want > 0 vnodes of the removed node")
+	}
+	if have, want := h3dist.Progress().Pending, draining; have < want {
+		t.Fatalf("have %d, want >= %d", have, want)
+	}
+	for !h3dist.Progress().Done() {
+		h3dist.Step()
+	}
+	h3dist.EachVNode(func(_ uint64, addr string) bool {
+		if addr == "host-2.com" {
+			t.Fatalf("have vnode of the removed node")
+		}
+		return true
+	})
+	report := h3dist.DistributionReport()
+	if have, want := len(report.Hosts), 2; have != want {
+		t.Fatalf("have %d, want %d hosts", have, want)
+	}
+}
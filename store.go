@@ -0,0 +1,286 @@
+package h3geodist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Topology is a type to represent the shared list of nodes
+// persisted by a TopologyStore with its version.
+type Topology struct {
+	// Version is the number of the stored changes, 0 if nothing is stored.
+	Version uint64   `json:"version"`
+	Nodes   []string `json:"nodes"`
+}
+
+// TopologyStore is a shared storage of the topology
+// updated by compare-and-swap.
+type TopologyStore interface {
+	// Load returns the stored topology.
+	Load(ctx context.Context) (Topology, error)
+	// CompareAndSwap stores the nodes of t if the stored version equals t.Version
+	// and returns the stored topology with the next version.
+	// It returns ErrVersionConflict if the version differs.
+	CompareAndSwap(ctx context.Context, t Topology) (Topology, error)
+}
+
+// Coordinator applies the nodes of a TopologyStore to a Distributed,
+// so that several controllers add and remove nodes without lost updates.
+// Thread-safe.
+type Coordinator struct {
+	d        *Distributed
+	store    TopologyStore
+	interval time.Duration
+	mu       sync.Mutex
+	version  uint64
+}
+
+// NewCoordinator creates and returns a new coordinator of the distribution.
+// Replicas reload the topology every interval while following the coordinator:
+//
+//	c := h3geodist.NewCoordinator(h3dist, store, time.Second)
+//	go h3dist.Follow(ctx, c, 0)
+func NewCoordinator(d *Distributed, store TopologyStore, interval time.Duration) *Coordinator {
+	return &Coordinator{d: d, store: store, interval: interval}
+}
+
+// Version returns the version of the topology last applied by Add, Remove or Sync.
+func (c *Coordinator) Version() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// Add adds the node to the stored topology and applies it.
+// Conflicting updates are retried on the reloaded topology.
+func (c *Coordinator) Add(ctx context.Context, addr string) error {
+	return c.update(ctx, func(nodes []string) ([]string, bool) {
+		for _, n := range nodes {
+			if n == addr {
+				return nodes, false
+			}
+		}
+		return append(nodes, addr), true
+	})
+}
+
+// Remove removes the node from the stored topology and applies it.
+// Conflicting updates are retried on the reloaded topology.
+func (c *Coordinator) Remove(ctx context.Context, addr string) error {
+	return c.update(ctx, func(nodes []string) ([]string, bool) {
+		res := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			if n != addr {
+				res = append(res, n)
+			}
+		}
+		return res, len(res) != len(nodes)
+	})
+}
+
+// Sync loads the stored topology and applies it if the version changed.
+func (c *Coordinator) Sync(ctx context.Context) error {
+	t, err := c.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	return c.apply(t)
+}
+
+// Watch calls fn with the stored nodes on every version change
+// until the context is done, see MembershipSource.
+// The nodes are applied by the consumer, such as Follow with its debounce,
+// so Watch changes neither the Distributed nor Version.
+// Nothing is called while the store is empty
+// or for versions already applied by Add, Remove or Sync.
+func (c *Coordinator) Watch(ctx context.Context, fn func(members []string)) error {
+	var last uint64
+	return poll(ctx, c.interval, func() error {
+		t, err := c.store.Load(ctx)
+		if err != nil {
+			return err
+		}
+		if t.Version == last || t.Version <= c.Version() {
+			return nil
+		}
+		last = t.Version
+		fn(t.Nodes)
+		return nil
+	})
+}
+
+func (c *Coordinator) update(ctx context.Context, fn func(nodes []string) ([]string, bool)) error {
+	for {
+		t, err := c.store.Load(ctx)
+		if err != nil {
+			return err
+		}
+		nodes, changed := fn(append([]string(nil), t.Nodes...))
+		if !changed {
+			return c.apply(t)
+		}
+		sort.Strings(nodes)
+		next, err := c.store.CompareAndSwap(ctx, Topology{Version: t.Version, Nodes: nodes})
+		if errors.Is(err, ErrVersionConflict) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return c.apply(next)
+	}
+}
+
+// apply sets the nodes of the topology newer than the applied one.
+// The version is raised only after the nodes are set.
+func (c *Coordinator) apply(t Topology) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.Version <= c.version {
+		return nil
+	}
+	if err := c.d.SetNodes(t.Nodes); err != nil {
+		return err
+	}
+	c.version = t.Version
+	return nil
+}
+
+// MemoryStore is the in-memory TopologyStore shared within the process.
+// Thread-safe.
+type MemoryStore struct {
+	mu sync.Mutex
+	t  Topology
+}
+
+// NewMemoryStore creates and returns a new empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load returns the stored topology.
+func (s *MemoryStore) Load(_ context.Context) (Topology, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.clone(), nil
+}
+
+// CompareAndSwap stores the nodes of t if the stored version equals t.Version.
+func (s *MemoryStore) CompareAndSwap(_ context.Context, t Topology) (Topology, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.t.Version != t.Version {
+		return Topology{}, ErrVersionConflict
+	}
+	s.t = Topology{Version: t.Version + 1, Nodes: t.Nodes}.clone()
+	return s.t.clone(), nil
+}
+
+// FileStore is the TopologyStore in a JSON file shared by processes
+// on the same filesystem. Writers are serialized by the lock of the operating system
+// on the lock file next to the store file, so the lock of a crashed writer
+// is released with its process. The lock file is left in place,
+// the store file itself is replaced atomically.
+type FileStore struct {
+	filename string
+}
+
+// NewFileStore returns the store in the file.
+// A missing file means an empty topology.
+func NewFileStore(filename string) *FileStore {
+	return &FileStore{filename: filename}
+}
+
+// Load returns the stored topology.
+func (s *FileStore) Load(_ context.Context) (Topology, error) {
+	var t Topology
+	data, err := os.ReadFile(s.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// CompareAndSwap stores the nodes of t if the stored version equals t.Version.
+func (s *FileStore) CompareAndSwap(ctx context.Context, t Topology) (Topology, error) {
+	unlock, err := s.lock(ctx)
+	if err != nil {
+		return Topology{}, err
+	}
+	defer unlock()
+	cur, err := s.Load(ctx)
+	if err != nil {
+		return Topology{}, err
+	}
+	if cur.Version != t.Version {
+		return Topology{}, ErrVersionConflict
+	}
+	next := Topology{Version: t.Version + 1, Nodes: t.Nodes}.clone()
+	data, err := json.Marshal(next)
+	if err != nil {
+		return Topology{}, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.filename), filepath.Base(s.filename)+".*")
+	if err != nil {
+		return Topology{}, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return Topology{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return Topology{}, err
+	}
+	if err := os.Rename(tmp.Name(), s.filename); err != nil {
+		return Topology{}, err
+	}
+	return next, nil
+}
+
+// lock takes the lock of the lock file, waiting for other writers
+// until the context is done.
+func (s *FileStore) lock(ctx context.Context) (unlock func(), err error) {
+	f, err := os.OpenFile(s.filename+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if ok {
+			return func() {
+				_ = unlockFile(f)
+				_ = f.Close()
+			}, nil
+		}
+		select {
+		case <-ctx.Done():
+			_ = f.Close()
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func (t Topology) clone() Topology {
+	return Topology{Version: t.Version, Nodes: append([]string(nil), t.Nodes...)}
+}
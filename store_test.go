@@ -0,0 +1,167 @@
+package h3geodist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testCoordinators(t *testing.T, store TopologyStore) {
+	const writers = 4
+	ctx := context.Background()
+	dists := make([]*Distributed, writers)
+	coords := make([]*Coordinator, writers)
+	for i := range dists {
+		h3dist, err := New(Level2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dists[i] = h3dist
+		coords[i] = NewCoordinator(h3dist, store, 10*time.Millisecond)
+	}
+	var wg sync.WaitGroup
+	want := make([]string, 0, writers*2)
+	for i := range coords {
+		for j := 0; j < 2; j++ {
+			want = append(want, fmt.Sprintf("host-%d-%d.com", i, j))
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 2; j++ {
+				if err := coords[i].Add(ctx, fmt.Sprintf("host-%d-%d.com", i, j)); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	sort.Strings(want)
+	stored, err := store.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := stored.Nodes, want; !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+	if have, want := stored.Version, uint64(writers*2); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	for i, c := range coords {
+		if err := c.Sync(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := c.Version(), stored.Version; have != want {
+			t.Fatalf("have %d, want %d", have, want)
+		}
+		waitNodes(t, dists[i], want)
+	}
+
+	if err := coords[0].Remove(ctx, want[0]); err != nil {
+		t.Fatal(err)
+	}
+	replicaCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		_ = dists[1].Follow(replicaCtx, coords[1], 0)
+	}()
+	waitNodes(t, dists[1], want[1:])
+	if !reflect.DeepEqual(dists[0].Snapshot(), dists[1].Snapshot()) {
+		t.Fatalf("have different snapshots, want equal")
+	}
+
+	_, err = store.CompareAndSwap(ctx, Topology{Version: 1, Nodes: want})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("have %v, want %v", err, ErrVersionConflict)
+	}
+}
+
+func TestCoordinator_MemoryStore(t *testing.T) {
+	testCoordinators(t, NewMemoryStore())
+}
+
+func TestCoordinator_FileStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "topology.json")
+	store := NewFileStore(filename)
+	stored, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := stored.Version, uint64(0); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	testCoordinators(t, store)
+}
+
+func TestFileStore_Lock(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "topology.json")
+	// the lock file left by a crashed writer is not locked
+	if err := os.WriteFile(filename+".lock", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewFileStore(filename)
+	if _, err := store.CompareAndSwap(ctx, Topology{Nodes: []string{"host-a.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := store.lock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = store.CompareAndSwap(timeoutCtx, Topology{Version: 1, Nodes: []string{"host-b.com"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("have %v, want %v", err, context.DeadlineExceeded)
+	}
+	unlock()
+	if _, err := store.CompareAndSwap(ctx, Topology{Version: 1, Nodes: []string{"host-b.com"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCoordinator_Watch(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	if _, err := store.CompareAndSwap(ctx, Topology{Nodes: []string{"host-a.com", "host-b.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	h3dist, err := New(Level2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCoordinator(h3dist, store, time.Second)
+	watchCtx, cancel := context.WithCancel(ctx)
+	var reported []string
+	err = c.Watch(watchCtx, func(members []string) {
+		reported = members
+		cancel()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := reported, []string{"host-a.com", "host-b.com"}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+	// the watcher only reports the nodes, the consumer applies them
+	if have := h3dist.Nodes(); len(have) != 0 {
+		t.Fatalf("have %v, want no nodes", have)
+	}
+	if have, want := c.Version(), uint64(0); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if err := c.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := c.Version(), uint64(1); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	waitNodes(t, h3dist, []string{"host-a.com", "host-b.com"})
+}
@@ -0,0 +1,267 @@
+package h3geodist
+
+import (
+	"math"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// Polygon is a type to represent an outline of connected cells
+// with an outer loop and zero or more holes.
+// The outer loop is counter-clockwise, holes are clockwise.
+// Loops are open, the last vertex is not repeated.
+type Polygon struct {
+	Outer []LatLon
+	Holes [][]LatLon
+}
+
+// BBox is a type to represent a bounding box.
+// Boxes of territories crossing the antimeridian span all longitudes.
+type BBox struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// Territory is a type to represent the area covered by the host cells.
+type Territory struct {
+	Host     string
+	Polygons []Polygon
+	BBox     BBox
+	Centroid LatLon
+	AreaKm2  float64
+	Cells    int
+}
+
+// Territory returns the merged polygons of all cells owned by the host,
+// together with its bounding box, area weighted centroid, area and number of cells.
+// Shards of hot cells are merged separately from the cells of the level.
+// The territory of a single host covering the whole globe has no polygons.
+func (d *Distributed) Territory(host string) (t Territory, err error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if !d.exist(host) {
+		return t, ErrNodeNotFound
+	}
+	return territoryOf(d.partition(), host, d.lookup), nil
+}
+
+// territoryOf returns the territory of the host in the partition
+// with the owners of cells returned by lookup.
+func territoryOf(p partition, host string, lookup func(cell h3.H3Index) (string, bool)) (t Territory) {
+	t.Host = host
+	t.BBox = BBox{MinLat: 90, MinLon: 180, MaxLat: -90, MaxLon: -180}
+	owned := make([]h3.H3Index, 0, 64)
+	var center vec3
+	p.eachShard(func(cell h3.H3Index) {
+		addr, ok := lookup(cell)
+		if !ok || addr != host {
+			return
+		}
+		owned = append(owned, cell)
+		area := h3.CellAreaKm2(cell)
+		t.AreaKm2 += area
+		center = center.add(toVec(latLonFromGeo(h3.ToGeo(cell))).scale(area))
+		for _, vertex := range h3.ToGeoBoundary(cell) {
+			t.BBox.extend(latLonFromGeo(vertex))
+		}
+	})
+	t.Cells = len(owned)
+	if t.Cells == 0 {
+		t.BBox = BBox{}
+		return t
+	}
+	if center.norm() > 0 {
+		t.Centroid = fromVec(center)
+	}
+	for _, component := range components(owned) {
+		if polygon, ok := polygonFromSet(component); ok {
+			t.Polygons = append(t.Polygons, polygon)
+		}
+	}
+	return t
+}
+
+func (b *BBox) extend(ll LatLon) {
+	b.MinLat = math.Min(b.MinLat, ll.Lat)
+	b.MinLon = math.Min(b.MinLon, ll.Lon)
+	b.MaxLat = math.Max(b.MaxLat, ll.Lat)
+	b.MaxLon = math.Max(b.MaxLon, ll.Lon)
+}
+
+// components splits the cells into groups connected by edges.
+func components(cells []h3.H3Index) [][]h3.H3Index {
+	set := make(map[h3.H3Index]bool, len(cells))
+	for _, cell := range cells {
+		set[cell] = false
+	}
+	groups := make([][]h3.H3Index, 0, 4)
+	for _, cell := range cells {
+		if set[cell] {
+			continue
+		}
+		set[cell] = true
+		group := []h3.H3Index{cell}
+		for i := 0; i < len(group); i++ {
+			for _, next := range h3.KRing(group[i], 1) {
+				if visited, ok := set[next]; !ok || visited {
+					continue
+				}
+				set[next] = true
+				group = append(group, next)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// polygonFromSet returns the outline of the connected set of cells.
+// Edges shared by two cells cancel out, the rest are chained into loops
+// that keep the cells on the left. The loop with the largest area
+// on the right side is the outer one, others are holes.
+// A set covering the whole globe has no outline.
+func polygonFromSet(cells []h3.H3Index) (p Polygon, ok bool) {
+	var o outline
+	for _, cell := range cells {
+		boundary := h3.ToGeoBoundary(cell)
+		for i := 0; i < len(boundary); i++ {
+			o.add(latLonFromGeo(boundary[i]), latLonFromGeo(boundary[(i+1)%len(boundary)]))
+		}
+	}
+	loops := o.loops()
+	if len(loops) == 0 {
+		return p, false
+	}
+	outer, minArea := 0, math.Inf(1)
+	for i, loop := range loops {
+		if area := leftArea(loop); area < minArea {
+			outer, minArea = i, area
+		}
+	}
+	for i, loop := range loops {
+		if i == outer {
+			p.Outer = loop
+		} else {
+			p.Holes = append(p.Holes, loop)
+		}
+	}
+	return p, true
+}
+
+// vertexScale is the precision of vertex matching (1e-6 degrees).
+// Vertices on icosahedron edges computed from different faces differ slightly.
+const vertexScale = 1e6
+
+type vertexKey struct {
+	lat int64
+	lon int64
+}
+
+type edgeKey struct {
+	from vertexKey
+	to   vertexKey
+}
+
+// outline collects directed edges of cells
+// and cancels out the edges shared by two cells.
+type outline struct {
+	coords map[vertexKey]LatLon
+	edges  map[edgeKey]int
+	order  []edgeKey
+}
+
+func (o *outline) add(from, to LatLon) {
+	if o.coords == nil {
+		o.coords = make(map[vertexKey]LatLon)
+		o.edges = make(map[edgeKey]int)
+	}
+	e := edgeKey{from: o.snap(from), to: o.snap(to)}
+	if e.from == e.to {
+		return
+	}
+	reverse := edgeKey{from: e.to, to: e.from}
+	if i, ok := o.edges[reverse]; ok {
+		delete(o.edges, reverse)
+		o.order[i] = edgeKey{}
+		return
+	}
+	o.edges[e] = len(o.order)
+	o.order = append(o.order, e)
+}
+
+// snap returns the key of the known vertex close to ll,
+// so that the same vertex computed from different cells matches.
+func (o *outline) snap(ll LatLon) vertexKey {
+	k := vertexKey{
+		lat: int64(math.Round(ll.Lat * vertexScale)),
+		lon: int64(math.Round(ll.Lon * vertexScale)),
+	}
+	for dlat := int64(-1); dlat <= 1; dlat++ {
+		for dlon := int64(-1); dlon <= 1; dlon++ {
+			near := vertexKey{lat: k.lat + dlat, lon: k.lon + dlon}
+			if _, ok := o.coords[near]; ok {
+				return near
+			}
+		}
+	}
+	o.coords[k] = ll
+	return k
+}
+
+// loops chains the remaining edges into closed loops.
+func (o *outline) loops() [][]LatLon {
+	outgoing := make(map[vertexKey][]int, len(o.edges))
+	for i, e := range o.order {
+		if o.alive(e, i) {
+			outgoing[e.from] = append(outgoing[e.from], i)
+		}
+	}
+	used := make([]bool, len(o.order))
+	loops := make([][]LatLon, 0, 1)
+	for i, e := range o.order {
+		if used[i] || !o.alive(e, i) {
+			continue
+		}
+		loop := make([]LatLon, 0, 6)
+		for next := i; next >= 0; {
+			used[next] = true
+			cur := o.order[next]
+			loop = append(loop, o.coords[cur.from])
+			next = -1
+			for _, j := range outgoing[cur.to] {
+				if !used[j] {
+					next = j
+					break
+				}
+			}
+		}
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+// alive checks if the edge at position i was not cancelled out.
+func (o *outline) alive(e edgeKey, i int) bool {
+	j, ok := o.edges[e]
+	return ok && j == i
+}
+
+// leftArea returns the area (steradians) of the sphere
+// on the left side of the closed loop.
+func leftArea(loop []LatLon) float64 {
+	pole := vec3{0, 0, 1}
+	var area float64
+	for i := 0; i < len(loop); i++ {
+		a, b := toVec(loop[i]), toVec(loop[(i+1)%len(loop)])
+		num := pole.dot(a.cross(b))
+		den := 1 + pole.dot(a) + a.dot(b) + b.dot(pole)
+		area += 2 * math.Atan2(num, den)
+	}
+	area = math.Mod(area, 4*math.Pi)
+	if area < 0 {
+		area += 4 * math.Pi
+	}
+	return area
+}
@@ -0,0 +1,101 @@
+package h3geodist
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_Territory(t *testing.T) {
+	h3dist, err := New(Level3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"}
+	for _, host := range hosts {
+		_ = h3dist.Add(host)
+	}
+
+	stats := make(map[string]int)
+	h3dist.EachCell(func(c Cell) {
+		stats[c.Host]++
+	})
+
+	var area float64
+	for _, host := range hosts {
+		territory, err := h3dist.Territory(host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := territory.Cells, stats[host]; have != want {
+			t.Fatalf("have %d, want %d cells", have, want)
+		}
+		if len(territory.Polygons) == 0 {
+			t.Fatalf("have 0, want > 0 polygons")
+		}
+		for _, polygon := range territory.Polygons {
+			if len(polygon.Outer) < 5 {
+				t.Fatalf("have %d, want >= 5 vertices", len(polygon.Outer))
+			}
+		}
+		box := territory.BBox
+		if box.MinLat > box.MaxLat || box.MinLon > box.MaxLon {
+			t.Fatalf("invalid bounding box %v", box)
+		}
+		area += territory.AreaKm2
+	}
+	// surface area of the Earth
+	if want := 510065621.7; math.Abs(area-want)/want > 1e-3 {
+		t.Fatalf("have %f, want %f km2", area, want)
+	}
+}
+
+func TestDistributed_TerritoryPolygons(t *testing.T) {
+	h3dist, _ := New(Level3)
+	_ = h3dist.Add("127.0.0.1")
+	cells := h3.KRing(h3.FromString("83821cfffffffff"), 2)
+	groups := components(cells)
+	if have, want := len(groups), 1; have != want {
+		t.Fatalf("have %d, want %d components", have, want)
+	}
+	polygon, ok := polygonFromSet(groups[0])
+	if !ok {
+		t.Fatalf("have false, want true")
+	}
+	if have, want := len(polygon.Outer), 30; have != want {
+		t.Fatalf("have %d, want %d vertices", have, want)
+	}
+	if have, want := len(polygon.Holes), 0; have != want {
+		t.Fatalf("have %d, want %d holes", have, want)
+	}
+	// a ring of cells has a hole in the middle
+	ring, err := h3.HexRing(h3.FromString("83821cfffffffff"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	polygon, _ = polygonFromSet(ring)
+	if have, want := len(polygon.Holes), 1; have != want {
+		t.Fatalf("have %d, want %d holes", have, want)
+	}
+
+	territory, err := h3dist.Territory("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := uint(territory.Cells), NumCells(Level3); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+	if have, want := len(territory.Polygons), 0; have != want {
+		t.Fatalf("have %d, want %d polygons", have, want)
+	}
+}
+
+func TestDistributed_TerritoryWithError(t *testing.T) {
+	h3dist, _ := New(Level1)
+	_ = h3dist.Add("127.0.0.1")
+	if _, err := h3dist.Territory("127.0.0.2"); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("have %v, want %v error", err, ErrNodeNotFound)
+	}
+}
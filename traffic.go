@@ -0,0 +1,246 @@
+package h3geodist
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/uber/h3-go/v3"
+)
+
+const (
+	// sketchDepth and sketchWidth are the dimensions of the count-min sketch,
+	// the error of an estimate is within e/sketchWidth of all lookups
+	// with the probability 1-exp(-sketchDepth).
+	sketchDepth = 4
+	sketchWidth = 2048
+
+	// maxDecayExp is the exponent of the landmark weight
+	// after which the counters are rescaled.
+	maxDecayExp = 64
+)
+
+// WithTraffic enables the accounting of lookups per vnode, per host
+// and of the topK hottest cells. Counters decay exponentially
+// with the half-life, so they reflect the recent load.
+// Lookup, LookupFromLatLon and LookupMany are counted.
+func WithTraffic(halfLife time.Duration, topK int) Option {
+	return func(d *Distributed) {
+		if halfLife <= 0 || topK < 0 {
+			return
+		}
+		d.traffic = newTraffic(halfLife, topK)
+	}
+}
+
+// TrafficReport is a type to represent the measured lookups
+// compared with the vnode share of each host.
+type TrafficReport struct {
+	HalfLife time.Duration
+	// Lookups is the decayed number of all lookups.
+	Lookups float64
+	// VNodes holds the lookups of each vnode.
+	VNodes []float64
+	// Hosts is sorted by host.
+	Hosts []HostTraffic
+	// HotCells holds the hottest cells sorted by lookups in descending order.
+	HotCells []CellTraffic
+}
+
+// HostTraffic is a type to represent the measured lookups of a single host.
+type HostTraffic struct {
+	Host    string
+	Lookups float64
+	// Share is the percentage of lookups landed on the host.
+	Share float64
+	// ExpectedShare is the percentage of the load assumed by Stats.
+	ExpectedShare float64
+}
+
+// CellTraffic is a type to represent the estimated lookups of a cell.
+// The count-min sketch never underestimates the lookups.
+type CellTraffic struct {
+	Cell    Cell
+	Lookups float64
+}
+
+// TrafficReport returns the measured lookups.
+// The report is empty when the traffic accounting is not enabled.
+func (d *Distributed) TrafficReport() TrafficReport {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.traffic == nil {
+		return TrafficReport{}
+	}
+	t := d.traffic
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	scale := t.scale()
+	r := TrafficReport{
+		HalfLife: t.halfLife,
+		Lookups:  t.total * scale,
+		VNodes:   make([]float64, d.vnodes),
+		Hosts:    make([]HostTraffic, 0, len(d.nodes)),
+		HotCells: make([]CellTraffic, 0, len(t.top)),
+	}
+	for vnode, count := range t.vnodes {
+		if vnode < len(r.VNodes) {
+			r.VNodes[vnode] = count * scale
+		}
+	}
+	hosts := make(map[string]struct{}, len(d.nodes))
+	for _, n := range d.nodes {
+		hosts[n.addr] = struct{}{}
+	}
+	var load float64
+	for addr, value := range d.stats {
+		hosts[addr] = struct{}{}
+		load += value
+	}
+	for addr := range hosts {
+		host := HostTraffic{Host: addr, Lookups: t.hosts[addr] * scale}
+		if r.Lookups > 0 {
+			host.Share = host.Lookups / r.Lookups * 100
+		}
+		if load > 0 {
+			host.ExpectedShare = d.stats[addr] / load * 100
+		}
+		r.Hosts = append(r.Hosts, host)
+	}
+	sort.Slice(r.Hosts, func(i, j int) bool {
+		return r.Hosts[i].Host < r.Hosts[j].Host
+	})
+	for cell, count := range t.top {
+		c := CellTraffic{Cell: Cell{H3ID: cell}, Lookups: count * scale}
+		c.Cell.Host, _ = d.lookup(cell)
+		r.HotCells = append(r.HotCells, c)
+	}
+	sort.Slice(r.HotCells, func(i, j int) bool {
+		if r.HotCells[i].Lookups == r.HotCells[j].Lookups {
+			return r.HotCells[i].Cell.H3ID < r.HotCells[j].Cell.H3ID
+		}
+		return r.HotCells[i].Lookups > r.HotCells[j].Lookups
+	})
+	return r
+}
+
+// traffic holds exponentially decaying counters of lookups.
+// Instead of decaying each counter, increments are weighted
+// by the growing weight of the landmark time, see scale.
+type traffic struct {
+	mu       sync.Mutex
+	halfLife time.Duration
+	lambda   float64
+	landmark time.Time
+	now      func() time.Time
+	total    float64
+	vnodes   []float64
+	hosts    map[string]float64
+	sketch   [sketchDepth][sketchWidth]float64
+	topK     int
+	top      map[h3.H3Index]float64
+}
+
+func newTraffic(halfLife time.Duration, topK int) *traffic {
+	t := &traffic{
+		halfLife: halfLife,
+		lambda:   math.Ln2 / halfLife.Seconds(),
+		now:      time.Now,
+		hosts:    make(map[string]float64),
+		topK:     topK,
+		top:      make(map[h3.H3Index]float64, topK),
+	}
+	t.landmark = t.now()
+	return t
+}
+
+func (d *Distributed) countLookup(cell h3.H3Index, addr string) {
+	if d.traffic == nil {
+		return
+	}
+	d.traffic.count(cell, d.vnodes, addr)
+}
+
+// count adds a lookup of the distributed cell.
+func (t *traffic) count(cell h3.H3Index, vnodes uint64, addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w := t.weight()
+	vnode := int(uint2hash(uint64(cell)) % vnodes)
+	if vnode >= len(t.vnodes) {
+		t.vnodes = append(t.vnodes, make([]float64, int(vnodes)-len(t.vnodes))...)
+	}
+	t.total += w
+	t.vnodes[vnode] += w
+	t.hosts[addr] += w
+	if t.topK == 0 {
+		return
+	}
+	estimate := t.estimate(cell, w)
+	if _, ok := t.top[cell]; ok || len(t.top) < t.topK {
+		t.top[cell] = estimate
+		return
+	}
+	coldest, lowest := h3.H3Index(0), math.Inf(1)
+	for c, count := range t.top {
+		if count < lowest {
+			coldest, lowest = c, count
+		}
+	}
+	if estimate > lowest {
+		delete(t.top, coldest)
+		t.top[cell] = estimate
+	}
+}
+
+// estimate adds w to the sketch with the conservative update
+// and returns the estimate of the cell.
+func (t *traffic) estimate(cell h3.H3Index, w float64) float64 {
+	var slots [sketchDepth]int
+	lowest := math.Inf(1)
+	for i := 0; i < sketchDepth; i++ {
+		slots[i] = int(uint2hash(uint64(cell)+uint64(i)*0x9e3779b97f4a7c15) % sketchWidth)
+		lowest = math.Min(lowest, t.sketch[i][slots[i]])
+	}
+	estimate := lowest + w
+	for i := 0; i < sketchDepth; i++ {
+		if t.sketch[i][slots[i]] < estimate {
+			t.sketch[i][slots[i]] = estimate
+		}
+	}
+	return estimate
+}
+
+// weight returns the weight of a lookup now relative to the landmark.
+// Counters are rescaled and the landmark is moved when the weight grows too large.
+func (t *traffic) weight() float64 {
+	now := t.now()
+	exp := t.lambda * now.Sub(t.landmark).Seconds()
+	if exp <= maxDecayExp {
+		return math.Exp(exp)
+	}
+	scale := math.Exp(-exp)
+	t.total *= scale
+	for i := range t.vnodes {
+		t.vnodes[i] *= scale
+	}
+	for host := range t.hosts {
+		t.hosts[host] *= scale
+	}
+	for i := range t.sketch {
+		for j := range t.sketch[i] {
+			t.sketch[i][j] *= scale
+		}
+	}
+	for cell := range t.top {
+		t.top[cell] *= scale
+	}
+	t.landmark = now
+	return 1
+}
+
+// scale returns the factor that turns the weighted counters into decayed counts.
+func (t *traffic) scale() float64 {
+	return math.Exp(-t.lambda * t.now().Sub(t.landmark).Seconds())
+}
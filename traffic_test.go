@@ -0,0 +1,89 @@
+package h3geodist
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_TrafficReport(t *testing.T) {
+	h3dist, err := New(Level3, WithTraffic(time.Minute, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := h3dist.TrafficReport(); have.Lookups != 0 {
+		t.Fatalf("have %f, want 0", have.Lookups)
+	}
+	for i := 0; i < 4; i++ {
+		_ = h3dist.Add(fmt.Sprintf("host-%d.com", i))
+	}
+	now := time.Unix(0, 0)
+	h3dist.traffic.now = func() time.Time { return now }
+	h3dist.traffic.landmark = now
+
+	hot := h3.FromGeo(h3.GeoCoord{Latitude: 40.7128, Longitude: -74.0060}, Level3)
+	for i := 0; i < 1000; i++ {
+		h3dist.Lookup(hot)
+	}
+	var cells []h3.H3Index
+	Iter(Level3, func(_ uint, cell h3.H3Index) {
+		if len(cells) < 500 {
+			cells = append(cells, cell)
+		}
+	})
+	h3dist.LookupMany(cells, func(c Cell) bool { return true })
+	if _, err := h3dist.LookupFromLatLon(40.7128, -74.0060); err != nil {
+		t.Fatal(err)
+	}
+
+	r := h3dist.TrafficReport()
+	if have, want := r.Lookups, 1501.0; math.Abs(have-want) > 1e-6 {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	if have, want := len(r.VNodes), int(h3dist.VNodes()); have != want {
+		t.Fatalf("have %d, want %d vnodes", have, want)
+	}
+	if have, want := r.VNodes[h3dist.VNodeIndex(hot)], 1001.0; have < want {
+		t.Fatalf("have %f, want >= %f", have, want)
+	}
+	var share, expected float64
+	for _, host := range r.Hosts {
+		share += host.Share
+		expected += host.ExpectedShare
+	}
+	if math.Abs(share-100) > 1e-6 || math.Abs(expected-100) > 1e-6 {
+		t.Fatalf("have %f and %f, want 100", share, expected)
+	}
+	if have, want := len(r.HotCells), 3; have != want {
+		t.Fatalf("have %d, want %d hot cells", have, want)
+	}
+	top := r.HotCells[0]
+	if have, want := top.Cell.H3ID, hot; have != want {
+		t.Fatalf("have %v, want %v", h3.ToString(have), h3.ToString(want))
+	}
+	if top.Lookups < 1001 {
+		t.Fatalf("have %f, want >= 1001", top.Lookups)
+	}
+	if !h3dist.IsOwned(top.Cell) {
+		t.Fatalf("h3dist.IsOwned(%v) => false, expected true", top.Cell)
+	}
+
+	// counters halve after the half-life
+	now = now.Add(time.Minute)
+	if have, want := h3dist.TrafficReport().Lookups, 1501.0/2; math.Abs(have-want) > 1e-6 {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	// and survive the rescaling of the landmark
+	now = now.Add(2 * time.Hour)
+	h3dist.Lookup(hot)
+	r = h3dist.TrafficReport()
+	if have, want := r.Lookups, 1+1501*math.Pow(2, -121); math.Abs(have-want) > 1e-6 {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	if have, want := r.HotCells[0].Cell.H3ID, hot; have != want {
+		t.Fatalf("have %v, want %v", h3.ToString(have), h3.ToString(want))
+	}
+}
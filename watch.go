@@ -0,0 +1,214 @@
+package h3geodist
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// Event is a type to represent a change of the topology.
+type Event struct {
+	// Epoch is the number of the topology changes so far.
+	Epoch   uint64
+	Added   []string
+	Removed []string
+	// Moves holds the vnodes that changed hands sorted by vnode.
+	// From is empty for a vnode without a previous owner,
+	// To is empty for a vnode without a new owner.
+	Moves []Move
+	// Split holds the cells whose hot split level changed,
+	// so their shards changed.
+	Split []h3.H3Index
+}
+
+// Epoch returns the number of the topology changes so far.
+func (d *Distributed) Epoch() uint64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.epoch
+}
+
+// Watch calls fn on every topology change, in the order of epochs.
+// Events are delivered from a separate goroutine, so fn may call
+// the methods of Distributed. Call stop to stop watching.
+func (d *Distributed) Watch(fn func(e Event)) (stop func()) {
+	return d.watch("", fn)
+}
+
+// WatchHost calls fn on topology changes that affect the host:
+// the host is added or removed, vnodes move from or to the host,
+// or hot cells are split, which may move shards of any host.
+// Moves of the event are filtered by the host, see Watch.
+func (d *Distributed) WatchHost(host string, fn func(e Event)) (stop func()) {
+	return d.watch(host, fn)
+}
+
+func (d *Distributed) watch(host string, fn func(e Event)) func() {
+	w := &watcher{
+		host:   host,
+		fn:     fn,
+		signal: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	d.mu.Lock()
+	if d.watchers == nil {
+		d.watchers = make(map[*watcher]struct{})
+	}
+	d.watchers[w] = struct{}{}
+	d.mu.Unlock()
+	go w.run()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.mu.Lock()
+			delete(d.watchers, w)
+			d.mu.Unlock()
+			close(w.done)
+		})
+	}
+}
+
+// state is a type to represent the topology compared by publish.
+type state struct {
+	nodes  []string
+	owners []string
+	hot    map[h3.H3Index]int
+}
+
+func (d *Distributed) state() state {
+	t := state{
+		nodes:  make([]string, 0, len(d.nodes)),
+		owners: make([]string, d.vnodes),
+		hot:    make(map[h3.H3Index]int, len(d.hot)),
+	}
+	for _, n := range d.nodes {
+		t.nodes = append(t.nodes, n.addr)
+	}
+	sort.Strings(t.nodes)
+	for vnode, n := range d.index {
+		t.owners[vnode] = n.addr
+	}
+	for cell, level := range d.hot {
+		t.hot[cell] = level
+	}
+	return t
+}
+
+// publish compares the topology with the previous one
+// and queues the event to watchers if anything changed.
+// It must be called with the write lock held.
+func (d *Distributed) publish(prev state) {
+	cur := d.state()
+	e := Event{
+		Added:   difference(cur.nodes, prev.nodes),
+		Removed: difference(prev.nodes, cur.nodes),
+	}
+	for vnode := range cur.owners {
+		if from, to := prev.owners[vnode], cur.owners[vnode]; from != to {
+			e.Moves = append(e.Moves, Move{
+				VNode: uint64(vnode),
+				From:  from,
+				To:    to,
+				Load:  d.vnodeWeight(uint64(vnode)),
+			})
+		}
+	}
+	for cell, level := range cur.hot {
+		if prev.hot[cell] != level {
+			e.Split = append(e.Split, cell)
+		}
+	}
+	for cell := range prev.hot {
+		if _, ok := cur.hot[cell]; !ok {
+			e.Split = append(e.Split, cell)
+		}
+	}
+	if len(e.Added) == 0 && len(e.Removed) == 0 && len(e.Moves) == 0 && len(e.Split) == 0 {
+		return
+	}
+	sort.Slice(e.Split, func(i, j int) bool {
+		return e.Split[i] < e.Split[j]
+	})
+	d.epoch++
+	e.Epoch = d.epoch
+	for w := range d.watchers {
+		if ev, ok := e.filter(w.host); ok {
+			w.push(ev)
+		}
+	}
+}
+
+// filter returns the event with the changes affecting the host.
+func (e Event) filter(host string) (Event, bool) {
+	if host == "" {
+		return e, true
+	}
+	res := Event{Epoch: e.Epoch, Added: e.Added, Removed: e.Removed, Split: e.Split}
+	for _, m := range e.Moves {
+		if m.From == host || m.To == host {
+			res.Moves = append(res.Moves, m)
+		}
+	}
+	// shards of a split cell may be gained or lost by any host
+	affected := len(res.Moves) > 0 || len(res.Split) > 0
+	for _, addr := range append(append([]string(nil), e.Added...), e.Removed...) {
+		affected = affected || addr == host
+	}
+	return res, affected
+}
+
+// difference returns the sorted hosts of a missing in b.
+func difference(a, b []string) []string {
+	var res []string
+	for _, addr := range a {
+		i := sort.SearchStrings(b, addr)
+		if i < len(b) && b[i] == addr {
+			continue
+		}
+		res = append(res, addr)
+	}
+	return res
+}
+
+// watcher delivers queued events to fn in order.
+type watcher struct {
+	host   string
+	fn     func(e Event)
+	mu     sync.Mutex
+	queue  []Event
+	signal chan struct{}
+	done   chan struct{}
+}
+
+func (w *watcher) push(e Event) {
+	w.mu.Lock()
+	w.queue = append(w.queue, e)
+	w.mu.Unlock()
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) run() {
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.signal:
+		}
+		w.mu.Lock()
+		queue := w.queue
+		w.queue = nil
+		w.mu.Unlock()
+		for _, e := range queue {
+			select {
+			case <-w.done:
+				return
+			default:
+			}
+			w.fn(e)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package h3geodist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_Watch(t *testing.T) {
+	h3dist, err := New(Level3, WithVNodes(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan Event, 16)
+	stop := h3dist.Watch(func(e Event) {
+		// watchers may call back
+		_ = h3dist.Nodes()
+		events <- e
+	})
+	defer stop()
+	hostEvents := make(chan Event, 16)
+	stopHost := h3dist.WatchHost("host-b.com", func(e Event) {
+		hostEvents <- e
+	})
+	defer stopHost()
+
+	next := func(ch chan Event) Event {
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no event")
+		}
+		return Event{}
+	}
+
+	_ = h3dist.Add("host-a.com")
+	e := next(events)
+	if have, want := e.Epoch, uint64(1); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if len(e.Added) != 1 || e.Added[0] != "host-a.com" {
+		t.Fatalf("have %v, want [host-a.com]", e.Added)
+	}
+	if have, want := len(e.Moves), 64; have != want {
+		t.Fatalf("have %d, want %d moves", have, want)
+	}
+
+	_ = h3dist.Add("host-a.com")
+	_ = h3dist.Add("host-b.com")
+	e = next(events)
+	if have, want := e.Epoch, uint64(2); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	for _, m := range e.Moves {
+		if m.From != "host-a.com" || m.To != "host-b.com" {
+			t.Fatalf("unexpected move %+v", m)
+		}
+	}
+	if have, want := h3dist.Epoch(), uint64(2); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	// the host watcher skips the first event
+	e = next(hostEvents)
+	if have, want := e.Epoch, uint64(2); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	cell := h3.FromGeo(h3.GeoCoord{Latitude: 40.7128, Longitude: -74.0060}, Level3)
+	if err := h3dist.MarkHot(cell, Level4); err != nil {
+		t.Fatal(err)
+	}
+	e = next(events)
+	if len(e.Split) != 1 || e.Split[0] != cell || len(e.Moves) != 0 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	// splits may move shards of any host
+	e = next(hostEvents)
+	if have, want := e.Epoch, uint64(3); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+
+	h3dist.Remove("host-b.com")
+	e = next(events)
+	if len(e.Removed) != 1 || e.Removed[0] != "host-b.com" {
+		t.Fatalf("have %v, want [host-b.com]", e.Removed)
+	}
+	e = next(hostEvents)
+	if have, want := e.Epoch, uint64(4); have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	for _, m := range e.Moves {
+		if m.From != "host-b.com" {
+			t.Fatalf("unexpected move %+v", m)
+		}
+	}
+
+	stop()
+	stop()
+	_ = h3dist.Add("host-c.com")
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
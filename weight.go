@@ -0,0 +1,84 @@
+package h3geodist
+
+import "github.com/uber/h3-go/v3"
+
+// WeightFunc is a type to represent the weight of a cell at the distribution level,
+// for example the traffic or the population of the cell.
+// Negative weights are treated as zero.
+type WeightFunc func(cell h3.H3Index) float64
+
+// WithCellWeight sets the weight function of cells.
+// Vnodes are balanced by the total weight of their cells
+// instead of the number of vnodes per node.
+func WithCellWeight(fn WeightFunc) Option {
+	return func(d *Distributed) {
+		d.weightFn = fn
+	}
+}
+
+// WithWeightTable sets the weights of cells from the table.
+// Cells of a finer level are aggregated up to the distribution level,
+// cells of a coarser level are ignored. Missing cells have zero weight.
+func WithWeightTable(table WeightTable) Option {
+	return func(d *Distributed) {
+		weights := table.Aggregate(d.level)
+		d.weightFn = func(cell h3.H3Index) float64 {
+			return weights[cell]
+		}
+	}
+}
+
+// VNodeWeight returns the total weight of the vnode cells.
+// Without cell weights and a coverage each vnode has weight 1.
+func (d *Distributed) VNodeWeight(vnode uint64) float64 {
+	return d.vnodeWeight(vnode)
+}
+
+func (d *Distributed) vnodeWeight(vnode uint64) float64 {
+	if d.weights == nil {
+		return 1
+	}
+	if vnode >= uint64(len(d.weights)) {
+		return 0
+	}
+	return d.weights[vnode]
+}
+
+// updateWeights recomputes the weights of vnodes.
+// With a coverage and without cell weights each covered cell has weight 1.
+func (d *Distributed) updateWeights() {
+	fn := d.weightFn
+	if fn == nil && d.coverage != nil {
+		fn = func(h3.H3Index) float64 { return 1 }
+	}
+	if fn == nil {
+		return
+	}
+	d.weights, d.totalWeight = vnodeWeights(d.partition(), d.vnodes, fn)
+}
+
+// vnodeWeights returns the total weight of covered cells by vnodes.
+// The weight of a hot cell is spread evenly over its shards.
+func vnodeWeights(p partition, vnodes uint64, fn WeightFunc) (weights []float64, total float64) {
+	weights = make([]float64, vnodes)
+	if vnodes == 0 {
+		return
+	}
+	p.eachCell(func(cell h3.H3Index) {
+		weight := fn(cell)
+		if weight <= 0 {
+			return
+		}
+		total += weight
+		split, ok := p.hot[cell]
+		if !ok {
+			weights[uint2hash(uint64(cell))%vnodes] += weight
+			return
+		}
+		children := h3.ToChildren(cell, split)
+		for _, child := range children {
+			weights[uint2hash(uint64(child))%vnodes] += weight / float64(len(children))
+		}
+	})
+	return
+}
@@ -0,0 +1,163 @@
+package h3geodist
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestDistributed_WithCellWeight(t *testing.T) {
+	// a dense city surrounded by a sparse world
+	city := make(map[h3.H3Index]struct{})
+	for _, cell := range h3.KRing(h3.FromGeo(h3.GeoCoord{Latitude: 40.7128, Longitude: -74.0060}, Level3), 4) {
+		city[cell] = struct{}{}
+	}
+	weight := func(cell h3.H3Index) float64 {
+		if _, ok := city[cell]; ok {
+			return 10000
+		}
+		return 1
+	}
+	h3dist, err := New(Level3, WithVNodes(256), WithCellWeight(weight))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := h3dist.Add(fmt.Sprintf("host-%d.com", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var want float64
+	Iter(Level3, func(_ uint, cell h3.H3Index) {
+		want += weight(cell)
+	})
+	var total float64
+	for vnode := uint64(0); vnode < h3dist.VNodes(); vnode++ {
+		total += h3dist.VNodeWeight(vnode)
+	}
+	if math.Abs(total-want) > 1e-6 {
+		t.Fatalf("have %f, want %f total weight", total, want)
+	}
+
+	var load float64
+	for _, info := range h3dist.Stats() {
+		load += info.Load
+		if info.Load > h3dist.AvgLoad() {
+			t.Fatalf("have %f, want <= %f load of %s", info.Load, h3dist.AvgLoad(), info.Host)
+		}
+	}
+	if math.Abs(load-want) > 1e-6 {
+		t.Fatalf("have %f, want %f weighted load", load, want)
+	}
+
+	// weighted load of each host matches its cells
+	weighted := make(map[string]float64)
+	h3dist.EachCell(func(c Cell) {
+		weighted[c.Host] += weight(c.H3ID)
+	})
+	for _, info := range h3dist.Stats() {
+		if math.Abs(weighted[info.Host]-info.Load) > 1e-6 {
+			t.Fatalf("have %f, want %f load of %s", info.Load, weighted[info.Host], info.Host)
+		}
+	}
+}
+
+func TestDistributed_WithWeightTable(t *testing.T) {
+	parent := h3.FromString("83821cfffffffff")
+	table := map[h3.H3Index]float64{
+		parent:                           5,
+		h3.FromString("81757ffffffffff"): 100, // coarser level is ignored
+	}
+	for _, child := range h3.ToChildren(parent, Level5) {
+		table[child] = 1
+	}
+	h3dist, err := New(Level3, WithVNodes(16), WithWeightTable(table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vnode := uint64(h3dist.VNodeIndex(parent))
+	if have, want := h3dist.VNodeWeight(vnode), 5+float64(len(h3.ToChildren(parent, Level5))); have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	_ = h3dist.Add("127.0.0.1")
+	_ = h3dist.Add("127.0.0.2")
+	stats := h3dist.Stats()
+	if have, want := stats[0].Load+stats[1].Load, h3dist.VNodeWeight(vnode); have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	// a vnode heavier than the average load goes to an empty node
+	if stats[0].Load != 0 && stats[1].Load != 0 {
+		t.Fatalf("have %v, want a single loaded node", stats)
+	}
+}
+
+func TestDistributed_WithDominantVNodes(t *testing.T) {
+	h3dist, err := New(Level3, WithVNodes(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a few vnodes with cells far heavier than the rest of the world
+	dominant := make(map[h3.H3Index]struct{})
+	vnodes := make(map[int]struct{})
+	Iter(Level3, func(_ uint, cell h3.H3Index) {
+		vnode := h3dist.VNodeIndex(cell)
+		if _, ok := vnodes[vnode]; ok || len(vnodes) < 4 {
+			vnodes[vnode] = struct{}{}
+			dominant[cell] = struct{}{}
+		}
+	})
+	h3dist, err = New(Level3, WithVNodes(64), WithCellWeight(func(cell h3.H3Index) float64 {
+		if _, ok := dominant[cell]; ok {
+			return 1e6
+		}
+		return 1
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := h3dist.Add(fmt.Sprintf("host-%d.com", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	owned := make(map[string]struct{})
+	h3dist.EachVNode(func(_ uint64, addr string) bool {
+		owned[addr] = struct{}{}
+		return true
+	})
+	if have, want := len(owned), 6; have != want {
+		t.Fatalf("have %d, want %d hosts with vnodes", have, want)
+	}
+	var load float64
+	for _, info := range h3dist.Stats() {
+		load += info.Load
+	}
+	if have, want := load, h3dist.totalWeight; math.Abs(have-want) > 1e-6 {
+		t.Fatalf("have %f, want %f weighted load", have, want)
+	}
+}
+
+func TestDistributed_VNodeWeight(t *testing.T) {
+	h3dist := Default()
+	if have, want := h3dist.VNodeWeight(0), 1.0; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+}
+
+func TestDistributed_DistributionReportWithCellWeight(t *testing.T) {
+	h3dist, _ := New(Level2, WithCellWeight(func(cell h3.H3Index) float64 {
+		return 0.5
+	}))
+	_ = h3dist.Add("127.0.0.1")
+	_ = h3dist.Add("127.0.0.2")
+	var vnodes int
+	for _, host := range h3dist.DistributionReport().Hosts {
+		vnodes += host.VNodes
+	}
+	if have, want := uint64(vnodes), h3dist.VNodes(); have != want {
+		t.Fatalf("have %d, want %d vnodes", have, want)
+	}
+}
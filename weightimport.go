@@ -0,0 +1,131 @@
+package h3geodist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// ImportWeightsCSV reads samples from the CSV with columns lat, lon and weight
+// and returns the table of summed weights at the level.
+// The first line is skipped if it is a header.
+func ImportWeightsCSV(r io.Reader, level int) (WeightTable, error) {
+	if ok := validateLevel(level); !ok {
+		return nil, unsupportedLevel(level)
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 3
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+	table := make(WeightTable)
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("h3geodist: weights csv: %w", err)
+		}
+		var sample [3]float64
+		for i := range sample {
+			sample[i], err = strconv.ParseFloat(record[i], 64)
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("h3geodist: weights csv line %d: %w", line, err)
+		}
+		table.add(LatLon{Lat: sample[0], Lon: sample[1]}, sample[2], level)
+	}
+	return table, nil
+}
+
+// ImportWeightsASCIIGrid reads the raster in the ESRI ASCII grid format
+// (ncols, nrows, xllcorner, yllcorner, cellsize, NODATA_value header)
+// and returns the table at the level.
+// The value of each raster cell is added to the H3 cell containing its center,
+// so the raster should be finer than the cells of the level.
+func ImportWeightsASCIIGrid(r io.Reader, level int) (WeightTable, error) {
+	if ok := validateLevel(level); !ok {
+		return nil, unsupportedLevel(level)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	scanner.Split(bufio.ScanWords)
+	header := make(map[string]float64)
+	var token string
+	for scanner.Scan() {
+		token = scanner.Text()
+		if _, err := strconv.ParseFloat(token, 64); err == nil {
+			break
+		}
+		key := strings.ToLower(token)
+		if !scanner.Scan() {
+			break
+		}
+		value, err := strconv.ParseFloat(scanner.Text(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("h3geodist: ascii grid header %s: %w", key, err)
+		}
+		header[key] = value
+		token = ""
+	}
+	ncols, nrows := int(header["ncols"]), int(header["nrows"])
+	cellsize := header["cellsize"]
+	if ncols <= 0 || nrows <= 0 || cellsize <= 0 {
+		return nil, fmt.Errorf("h3geodist: ascii grid header: ncols, nrows and cellsize are required")
+	}
+	west, hasWest := header["xllcorner"]
+	south, hasSouth := header["yllcorner"]
+	if !hasWest {
+		west = header["xllcenter"] - cellsize/2
+	}
+	if !hasSouth {
+		south = header["yllcenter"] - cellsize/2
+	}
+	nodata, hasNodata := header["nodata_value"]
+	table := make(WeightTable)
+	for i := 0; i < nrows*ncols; i++ {
+		if i > 0 || token == "" {
+			if !scanner.Scan() {
+				return nil, fmt.Errorf("h3geodist: ascii grid: have %d, want %d values", i, nrows*ncols)
+			}
+			token = scanner.Text()
+		}
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("h3geodist: ascii grid value %d: %w", i, err)
+		}
+		if (hasNodata && value == nodata) || value == 0 {
+			continue
+		}
+		row, col := i/ncols, i%ncols
+		center := LatLon{
+			Lat: south + (float64(nrows-row)-0.5)*cellsize,
+			Lon: west + (float64(col)+0.5)*cellsize,
+		}
+		table.add(center, value, level)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("h3geodist: ascii grid: %w", err)
+	}
+	return table, nil
+}
+
+func (t WeightTable) add(ll LatLon, weight float64, level int) {
+	t[h3.FromGeo(ll.toGeo(), level)] += weight
+}
+
+func unsupportedLevel(level int) error {
+	return fmt.Errorf("h3geodist: unsupported level - got %d, expected [%d-%d]",
+		level, Level0, Level6)
+}
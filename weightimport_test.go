@@ -0,0 +1,80 @@
+package h3geodist
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestImportWeightsCSV(t *testing.T) {
+	data := `lat,lon,weight
+40.7128,-74.0060,100
+40.7130,-74.0062,50
+51.5072,-0.1276,10
+`
+	table, err := ImportWeightsCSV(strings.NewReader(data), Level5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nyc := h3.FromGeo(h3.GeoCoord{Latitude: 40.7128, Longitude: -74.0060}, Level5)
+	if have, want := table[nyc], 150.0; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	if have, want := len(table), 2; have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+
+	if _, err := ImportWeightsCSV(strings.NewReader("1,2,3\n1,2,x\n"), Level5); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	if _, err := ImportWeightsCSV(strings.NewReader("1,2\n"), Level5); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	if _, err := ImportWeightsCSV(strings.NewReader(""), Level6+1); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+}
+
+func TestImportWeightsASCIIGrid(t *testing.T) {
+	data := `ncols 4
+nrows 2
+xllcorner -74.02
+yllcorner 40.70
+cellsize 0.01
+NODATA_value -9999
+1 2 3 -9999
+5 6 7 8
+`
+	table, err := ImportWeightsASCIIGrid(strings.NewReader(data), Level4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, weight := range table {
+		total += weight
+	}
+	if have, want := total, 32.0; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	// the top left value is at the north west corner
+	cell := h3.FromGeo(h3.GeoCoord{Latitude: 40.715, Longitude: -74.015}, Level6)
+	table, err = ImportWeightsASCIIGrid(strings.NewReader(data), Level6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := table[cell]; have < 1 {
+		t.Fatalf("have %f, want >= 1", have)
+	}
+
+	for _, broken := range []string{
+		"ncols 2\nnrows 2\ncellsize 1\n1 2 3\n",
+		"ncols 2\nnrows 1\n1 2\n",
+		"ncols 2\nnrows 1\ncellsize 1\n1 x\n",
+		"ncols x\n",
+	} {
+		if _, err := ImportWeightsASCIIGrid(strings.NewReader(broken), Level3); err == nil {
+			t.Fatalf("have nil, want error for %q", broken)
+		}
+	}
+}
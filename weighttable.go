@@ -0,0 +1,189 @@
+package h3geodist
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/uber/h3-go/v3"
+)
+
+// weightTableMagic is the signature of the binary weight table format.
+const weightTableMagic = "H3GW"
+
+const weightTableVersion = 1
+
+// ErrWeightTable returns when the binary weight table is malformed.
+var ErrWeightTable = errors.New("h3geodist: malformed weight table")
+
+// WeightTable is a type to represent the weights of cells.
+type WeightTable map[h3.H3Index]float64
+
+// Aggregate returns the table at the level.
+// Weights of finer cells are summed up to their parents,
+// cells of a coarser level are skipped.
+func (t WeightTable) Aggregate(level int) WeightTable {
+	res := make(WeightTable, len(t))
+	for cell, weight := range t {
+		cellLevel := h3.Resolution(cell)
+		if cellLevel < level {
+			continue
+		}
+		if cellLevel > level {
+			cell = h3.ToParent(cell, level)
+		}
+		res[cell] += weight
+	}
+	return res
+}
+
+// Level returns the level of the table cells.
+// It returns -1 for an empty table or a table with cells of different levels.
+func (t WeightTable) Level() int {
+	level := -1
+	for cell := range t {
+		cellLevel := h3.Resolution(cell)
+		if level >= 0 && cellLevel != level {
+			return -1
+		}
+		level = cellLevel
+	}
+	return level
+}
+
+// WriteTo writes the table to w in a compact binary format:
+// the header with the level followed by cells sorted by index,
+// each as a delta from the previous key (uvarint) and a weight (float32).
+// The key of a cell holds only the base cell and the digits of the level.
+// All cells must be at the same level, use Aggregate otherwise,
+// and the level must be within [Level0-Level6].
+func (t WeightTable) WriteTo(w io.Writer) (int64, error) {
+	level := t.Level()
+	if level < 0 && len(t) > 0 {
+		return 0, fmt.Errorf("h3geodist: weight table has cells of different levels")
+	}
+	if len(t) > 0 && !validateLevel(level) {
+		return 0, unsupportedLevel(level)
+	}
+	cells := make([]h3.H3Index, 0, len(t))
+	for cell := range t {
+		cells = append(cells, cell)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		return cells[i] < cells[j]
+	})
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	buf := make([]byte, binary.MaxVarintLen64)
+	_, _ = cw.Write([]byte(weightTableMagic))
+	_, _ = cw.Write([]byte{weightTableVersion, byte(int8(level))})
+	_, _ = cw.Write(buf[:binary.PutUvarint(buf, uint64(len(cells)))])
+	var prev uint64
+	for _, cell := range cells {
+		key := cellKey(cell, level)
+		_, _ = cw.Write(buf[:binary.PutUvarint(buf, key-prev)])
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(t[cell])))
+		_, _ = cw.Write(buf[:4])
+		prev = key
+	}
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// ReadWeightTable reads the table in the binary format from r.
+func ReadWeightTable(r io.Reader) (WeightTable, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(weightTableMagic)+2)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, ErrWeightTable
+	}
+	if string(header[:len(weightTableMagic)]) != weightTableMagic ||
+		header[len(weightTableMagic)] != weightTableVersion {
+		return nil, ErrWeightTable
+	}
+	level := int(int8(header[len(weightTableMagic)+1]))
+	count, err := binary.ReadUvarint(br)
+	if err != nil || (count > 0 && !validateLevel(level)) {
+		return nil, ErrWeightTable
+	}
+	size := 1 << 20
+	if count < uint64(size) {
+		size = int(count)
+	}
+	table := make(WeightTable, size)
+	buf := make([]byte, 4)
+	var prev uint64
+	for i := uint64(0); i < count; i++ {
+		delta, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, ErrWeightTable
+		}
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, ErrWeightTable
+		}
+		prev += delta
+		table[keyCell(prev, level)] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf)))
+	}
+	return table, nil
+}
+
+// SaveWeightTable writes the table to the file in the binary format.
+func SaveWeightTable(filename string, t WeightTable) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if _, err := t.WriteTo(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadWeightTable reads the table from the file in the binary format.
+func LoadWeightTable(filename string) (WeightTable, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadWeightTable(f)
+}
+
+// digitsOffset returns the position of the last digit of the level in the index.
+func digitsOffset(level int) uint {
+	return uint(3 * (h3.MaxResolution - level))
+}
+
+// cellKey returns the base cell and the digits of the cell at the level.
+func cellKey(cell h3.H3Index, level int) uint64 {
+	return uint64(cell) >> digitsOffset(level) & (1<<(7+3*level) - 1)
+}
+
+// keyCell returns the cell by the key at the level.
+func keyCell(key uint64, level int) h3.H3Index {
+	offset := digitsOffset(level)
+	return h3.H3Index(1<<59 | uint64(level)<<52 | key<<offset | (1<<offset - 1))
+}
+
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
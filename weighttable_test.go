@@ -0,0 +1,124 @@
+package h3geodist
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/uber/h3-go/v3"
+)
+
+func TestWeightTable_Aggregate(t *testing.T) {
+	parent := h3.FromString("83821cfffffffff")
+	table := make(WeightTable)
+	for _, child := range h3.ToChildren(parent, Level5) {
+		table[child] = 0.5
+	}
+	table[h3.FromString("81757ffffffffff")] = 100
+	if have, want := table.Level(), -1; have != want {
+		t.Fatalf("have %d, want %d level", have, want)
+	}
+
+	aggregated := table.Aggregate(Level3)
+	if have, want := len(aggregated), 1; have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+	if have, want := aggregated[parent], 0.5*float64(len(h3.ToChildren(parent, Level5))); have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	if have, want := aggregated.Level(), Level3; have != want {
+		t.Fatalf("have %d, want %d level", have, want)
+	}
+}
+
+func TestWeightTable_WriteTo(t *testing.T) {
+	table := make(WeightTable)
+	Iter(Level2, func(index uint, cell h3.H3Index) {
+		if index%3 == 0 {
+			table[cell] = float64(index) / 4
+		}
+	})
+	var buf bytes.Buffer
+	n, err := table.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := n, int64(buf.Len()); have != want {
+		t.Fatalf("have %d, want %d bytes", have, want)
+	}
+	// far less than 8 bytes of index and 8 bytes of weight per cell
+	if size := buf.Len(); size > len(table)*10 {
+		t.Fatalf("have %d, want <= %d bytes", size, len(table)*10)
+	}
+	restored, err := ReadWeightTable(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(restored), len(table); have != want {
+		t.Fatalf("have %d, want %d cells", have, want)
+	}
+	for cell, weight := range table {
+		if have := restored[cell]; math.Abs(have-weight) > 1e-3 {
+			t.Fatalf("have %f, want %f weight", have, weight)
+		}
+	}
+
+	mixed := WeightTable{
+		h3.FromString("83821cfffffffff"): 1,
+		h3.FromString("81757ffffffffff"): 1,
+	}
+	if _, err := mixed.WriteTo(&buf); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+
+	fine := WeightTable{
+		h3.FromGeo(h3.GeoCoord{Latitude: 52.5, Longitude: 13.4}, 8): 1,
+	}
+	buf.Reset()
+	if _, err := fine.WriteTo(&buf); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+	if have, want := buf.Len(), 0; have != want {
+		t.Fatalf("have %d, want %d bytes", have, want)
+	}
+}
+
+func TestReadWeightTableWithError(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		[]byte("H3GX\x01\x03"),
+		[]byte("H3GW\x01\x03\x02\x01"),
+	} {
+		if _, err := ReadWeightTable(bytes.NewReader(data)); !errors.Is(err, ErrWeightTable) {
+			t.Fatalf("have %v, want %v error", err, ErrWeightTable)
+		}
+	}
+}
+
+func TestSaveWeightTable(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "weights.h3w")
+	table := WeightTable{h3.FromString("83821cfffffffff"): 42}
+	if err := SaveWeightTable(filename, table); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := LoadWeightTable(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := restored[h3.FromString("83821cfffffffff")], 42.0; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	h3dist, err := New(Level3, WithWeightTable(restored))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vnode := uint64(h3dist.VNodeIndex(h3.FromString("83821cfffffffff")))
+	if have, want := h3dist.VNodeWeight(vnode), 42.0; have != want {
+		t.Fatalf("have %f, want %f", have, want)
+	}
+	if _, err := LoadWeightTable(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatalf("have nil, want error")
+	}
+}